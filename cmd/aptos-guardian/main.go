@@ -69,17 +69,39 @@ func run(ctx context.Context, configPath string) error {
 	defer func() { _ = st.Close() }()
 
 	engine := incidents.NewEngine(st, cfg, nil)
+	ipFamilies := make(map[string][]string)
 	rpcNames := make([]string, 0, len(cfg.RPCProviders))
 	rpcURLs := make(map[string]string)
 	for _, p := range cfg.RPCProviders {
 		rpcNames = append(rpcNames, p.Name)
 		rpcURLs[p.Name] = p.URL
+		if len(p.IPFamilies) > 0 {
+			ipFamilies["rpc/"+p.Name] = p.IPFamilies
+		}
 	}
 	dappNames := make([]string, 0, len(cfg.Dapps))
 	dappURLs := make(map[string]string)
 	for _, d := range cfg.Dapps {
 		dappNames = append(dappNames, d.Name)
 		dappURLs[d.Name] = d.URL
+		if len(d.IPFamilies) > 0 {
+			ipFamilies["dapp/"+d.Name] = d.IPFamilies
+		}
+	}
+	tcpNames := make([]string, 0, len(cfg.TCPTargets))
+	tcpAddrs := make(map[string]string)
+	for _, t := range cfg.TCPTargets {
+		tcpNames = append(tcpNames, t.Name)
+		tcpAddrs[t.Name] = t.Address
+		if len(t.IPFamilies) > 0 {
+			ipFamilies["tcp/"+t.Name] = t.IPFamilies
+		}
+	}
+	nodeNames := make([]string, 0, len(cfg.NodeMetrics))
+	nodeURLs := make(map[string]string)
+	for _, n := range cfg.NodeMetrics {
+		nodeNames = append(nodeNames, n.Name)
+		nodeURLs[n.Name] = n.URL
 	}
 
 	var discordSession *discordgo.Session
@@ -97,9 +119,18 @@ func run(ctx context.Context, configPath string) error {
 		engine.OnIncidentClosed = func(ctx context.Context, inc *store.Incident) {
 			_ = alerter.PostIncidentClosed(ctx, inc)
 		}
+		engine.OnIncidentUpdated = func(ctx context.Context, inc *store.Incident, message string) {
+			_ = alerter.PostIncidentUpdate(ctx, inc, message)
+		}
+		engine.OnSeverityChanged = func(ctx context.Context, inc *store.Incident, previous string) {
+			_ = alerter.PostSeverityChange(ctx, inc, previous)
+		}
 	}
 
-	runner := monitor.NewRunner(cfg, st, nil)
+	runner, err := monitor.NewRunner(cfg, st, nil)
+	if err != nil {
+		return err
+	}
 	runner.SetIncidentEngine(engine)
 	go runner.Run(ctx)
 
@@ -114,19 +145,56 @@ func run(ctx context.Context, configPath string) error {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
+				if err := engine.ReviewReportIncidents(ctx); err != nil {
+					slog.Warn("review report incidents", "err", err)
+				}
+				slos, err := engine.ReviewSLOs(ctx)
+				if err != nil {
+					slog.Warn("review slos", "err", err)
+				}
+				for _, s := range slos {
+					metrics.SetSLO(s.Name, s.SLI/100, s.Objective/100, s.BudgetRemaining)
+					for i, w := range incidents.BurnWindows {
+						metrics.SetSLOBurnRate(s.Name, incidents.BurnWindowLabel(w), s.BurnRates[i])
+					}
+				}
 				list, _ := st.ListIncidents(ctx, store.IncidentStateOpen, 100)
 				metrics.SetIncidentsOpen(float64(len(list)))
 			}
 		}
 	}()
 
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for {
+			if err := st.RollupUptime(ctx); err != nil {
+				slog.Warn("rollup uptime", "err", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
 	handlers := &api.Handlers{
-		Store:     st,
-		Engine:    engine,
-		RPCNames:  rpcNames,
-		DappNames: dappNames,
-		RPCURLs:   rpcURLs,
-		DappURLs:  dappURLs,
+		Store:      st,
+		Engine:     engine,
+		RPCNames:   rpcNames,
+		DappNames:  dappNames,
+		RPCURLs:    rpcURLs,
+		DappURLs:   dappURLs,
+		TCPNames:   tcpNames,
+		TCPAddrs:   tcpAddrs,
+		NodeNames:  nodeNames,
+		NodeURLs:   nodeURLs,
+		IPFamilies: ipFamilies,
+		DependsOn:  cfg.Dependencies(),
+		Circuits:   runner,
+		Budgets:    runner,
+		AdminToken: cfg.Server.AdminToken,
 	}
 	webRoot := api.DefaultWebRoot()
 	mux := api.Router(handlers, cfg.Server.MetricsPath, promhttp.Handler(), webRoot)
@@ -148,7 +216,14 @@ func run(ctx context.Context, configPath string) error {
 			DMRefuseMsg:    cfg.Discord.DMRefuseMsg,
 		}
 		buildCtx := func(ctx context.Context) (*discordbot.CommandContext, error) {
-			return discordbot.BuildCommandContext(ctx, st, engine, rpcNames, dappNames)
+			cc, err := discordbot.BuildCommandContext(ctx, st, engine, rpcNames, dappNames)
+			if err != nil {
+				return nil, err
+			}
+			cc.Store = st
+			cc.Engine = engine
+			cc.Maintenance, _ = engine.Maintenances(ctx)
+			return cc, nil
 		}
 		bot := discordbot.NewBotWithSession(discordSession, botCfg, buildCtx, nil)
 		if err := bot.Open(); err != nil {
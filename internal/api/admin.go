@@ -0,0 +1,233 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorusys/aptos-guardian/internal/incidents"
+	"github.com/gorusys/aptos-guardian/internal/monitor"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+const (
+	maxIncidentMessage = 2000
+	maxIncidentActor   = 100
+)
+
+// BudgetReporter reports today's request usage against each RPC provider's
+// daily budget.
+type BudgetReporter interface {
+	RequestBudgets() []monitor.BudgetStatus
+}
+
+type BudgetStatus struct {
+	Provider        string `json:"provider"`
+	DailyBudget     int    `json:"daily_budget,omitempty"`
+	UsedToday       int    `json:"used_today"`
+	Remaining       *int   `json:"remaining,omitempty"`
+	Throttled       bool   `json:"throttled"`
+	MinIntervalSecs int64  `json:"min_interval_secs,omitempty"`
+}
+
+// requireAdmin guards the /v1/admin/ endpoints. Requests must send
+// "Authorization: Bearer <server.admin_token>"; with no token configured the
+// admin API is disabled.
+func (h *Handlers) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.AdminToken == "" {
+			http.Error(w, "admin API disabled", http.StatusForbidden)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.AdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="aptos-guardian"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *Handlers) AdminBudgets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	out := []BudgetStatus{}
+	if h.Budgets != nil {
+		for _, b := range h.Budgets.RequestBudgets() {
+			bs := BudgetStatus{Provider: b.Provider, DailyBudget: b.Daily, UsedToday: b.Used, Throttled: b.Throttled}
+			if b.Daily > 0 {
+				remaining := b.Remaining
+				bs.Remaining = &remaining
+				bs.MinIntervalSecs = int64(b.MinInterval.Seconds())
+			}
+			out = append(out, bs)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"providers": out})
+}
+
+type IncidentStateRequest struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+}
+
+type IncidentAckRequest struct {
+	By string `json:"by"`
+}
+
+// IncidentCreateRequest opens a manual incident. Entity is "<type>/<name>"
+// of a monitored entity or "announcement/<title>".
+type IncidentCreateRequest struct {
+	Entity   string `json:"entity"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	By       string `json:"by"`
+}
+
+// IncidentUpdateRequest posts an update, changes severity or resolves; the
+// message is required for updates only.
+type IncidentUpdateRequest struct {
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	By       string `json:"by"`
+}
+
+// AdminCreateIncident serves POST /v1/admin/incidents.
+func (h *Handlers) AdminCreateIncident(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Engine == nil {
+		http.Error(w, "incident engine unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var req IncidentCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	summary := strings.TrimSpace(trunc(req.Summary, maxIncidentMessage))
+	if summary == "" {
+		http.Error(w, "summary is required", http.StatusBadRequest)
+		return
+	}
+	entityType, name, _ := strings.Cut(strings.TrimSpace(req.Entity), "/")
+	inc, err := h.Engine.OpenManual(r.Context(), entityType, trunc(strings.TrimSpace(name), maxIncidentActor),
+		severityParam(req.Severity), summary, trunc(req.By, maxIncidentActor))
+	if !writeIncidentError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(h.incidentDetail(r.Context(), inc))
+}
+
+// severityParam upper-cases a requested severity; empty means WARN.
+func severityParam(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return store.SeverityWarn
+	}
+	return s
+}
+
+// adminIncidentRoute serves POST /v1/admin/incidents/{id}/ with the actions
+// state, ack, update, severity and resolve.
+func (h *Handlers) adminIncidentRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/admin/incidents/"), "/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid incident id", http.StatusBadRequest)
+		return
+	}
+	if h.Engine == nil {
+		http.Error(w, "incident engine unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var inc *store.Incident
+	switch action {
+	case "state":
+		var req IncidentStateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		inc, err = h.Engine.Transition(r.Context(), id, strings.ToLower(strings.TrimSpace(req.State)), trunc(req.Message, maxIncidentMessage))
+	case "ack":
+		var req IncidentAckRequest
+		// The body is optional; an empty one, chunked or not, is no actor.
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		inc, err = h.Engine.Acknowledge(r.Context(), id, trunc(req.By, maxIncidentActor))
+	case "update", "severity", "resolve":
+		var req IncidentUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		message, by := strings.TrimSpace(trunc(req.Message, maxIncidentMessage)), trunc(req.By, maxIncidentActor)
+		switch action {
+		case "update":
+			if message == "" {
+				http.Error(w, "message is required", http.StatusBadRequest)
+				return
+			}
+			inc, err = h.Engine.PostUpdate(r.Context(), id, message, by)
+		case "severity":
+			if strings.TrimSpace(req.Severity) == "" {
+				http.Error(w, "severity is required", http.StatusBadRequest)
+				return
+			}
+			inc, err = h.Engine.SetSeverity(r.Context(), id, severityParam(req.Severity), message)
+		default:
+			inc, err = h.Engine.Resolve(r.Context(), id, message, by)
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if !writeIncidentError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.incidentDetail(r.Context(), inc))
+}
+
+// writeIncidentError answers with the status for an engine error and
+// reports whether the request may proceed.
+func writeIncidentError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, incidents.ErrIncidentNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, incidents.ErrInvalidState):
+		http.Error(w, "state must be investigating, identified, monitoring or resolved", http.StatusBadRequest)
+	case errors.Is(err, incidents.ErrInvalidSeverity):
+		http.Error(w, "severity must be WARN or CRIT", http.StatusBadRequest)
+	case errors.Is(err, incidents.ErrUnknownEntity):
+		http.Error(w, "entity must be a monitored <type>/<name> or announcement/<title>", http.StatusBadRequest)
+	case errors.Is(err, incidents.ErrIncidentResolved):
+		http.Error(w, "incident already resolved", http.StatusConflict)
+	case errors.Is(err, incidents.ErrIncidentOpen):
+		http.Error(w, "entity already has an open incident", http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	return false
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/gorusys/aptos-guardian/internal/config"
 	"github.com/gorusys/aptos-guardian/internal/incidents"
 	"github.com/gorusys/aptos-guardian/internal/metrics"
 	"github.com/gorusys/aptos-guardian/internal/store"
@@ -18,6 +21,24 @@ type Handlers struct {
 	DappNames []string
 	RPCURLs   map[string]string
 	DappURLs  map[string]string
+	TCPNames  []string
+	TCPAddrs  map[string]string
+	NodeNames []string
+	NodeURLs  map[string]string
+	// IPFamilies lists forced IP families per entity, keyed by "entity_type/name".
+	IPFamilies map[string][]string
+	// DependsOn lists upstream entities per entity, both as "entity_type/name".
+	DependsOn map[string][]string
+	Circuits  CircuitReporter
+	Budgets   BudgetReporter
+	// AdminToken is the bearer token for /v1/admin/; empty disables it.
+	AdminToken string
+}
+
+// CircuitReporter tells which entities are in the open-circuit state and only
+// receive a lightweight probe at a reduced rate.
+type CircuitReporter interface {
+	CircuitOpen(entityType, name string) bool
 }
 
 func (h *Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
@@ -34,31 +55,81 @@ type StatusResponse struct {
 	RecommendedProvider string            `json:"recommended_provider"`
 	RPCProviders        []ProviderStatus  `json:"rpc_providers"`
 	Dapps               []DappStatus      `json:"dapps"`
+	TCPTargets          []TCPStatus       `json:"tcp_targets,omitempty"`
+	Nodes               []ProviderStatus  `json:"nodes,omitempty"`
 	OpenIncidents       []IncidentSummary `json:"open_incidents"`
+	// Maintenance lists active and upcoming maintenance windows.
+	Maintenance []MaintenanceStatus `json:"maintenance,omitempty"`
 }
 
 type ProviderStatus struct {
-	Name      string `json:"name"`
-	URL       string `json:"url"`
-	Healthy   bool   `json:"healthy"`
-	LatencyMs *int64 `json:"latency_ms,omitempty"`
-	LastError string `json:"last_error,omitempty"`
+	Name        string         `json:"name"`
+	URL         string         `json:"url"`
+	Healthy     bool           `json:"healthy"`
+	LatencyMs   *int64         `json:"latency_ms,omitempty"`
+	LastError   string         `json:"last_error,omitempty"`
+	CircuitOpen bool           `json:"circuit_open,omitempty"`
+	Families    []FamilyStatus `json:"families,omitempty"`
+	DependsOn   []string       `json:"depends_on,omitempty"`
 }
 
 type DappStatus struct {
+	Name        string         `json:"name"`
+	URL         string         `json:"url"`
+	Healthy     bool           `json:"healthy"`
+	LatencyMs   *int64         `json:"latency_ms,omitempty"`
+	LastError   string         `json:"last_error,omitempty"`
+	CircuitOpen bool           `json:"circuit_open,omitempty"`
+	Families    []FamilyStatus `json:"families,omitempty"`
+	Steps       []StepStatus   `json:"steps,omitempty"`
+	DependsOn   []string       `json:"depends_on,omitempty"`
+}
+
+// StepStatus is one step of the latest journey check of a dApp.
+type StepStatus struct {
 	Name      string `json:"name"`
-	URL       string `json:"url"`
-	Healthy   bool   `json:"healthy"`
-	LatencyMs *int64 `json:"latency_ms,omitempty"`
+	Success   bool   `json:"success"`
+	Status    int    `json:"status,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+	LastError string `json:"last_error,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+type FamilyStatus struct {
+	Family      string `json:"family"`
+	Healthy     bool   `json:"healthy"`
+	LatencyMs   *int64 `json:"latency_ms,omitempty"`
+	LastError   string `json:"last_error,omitempty"`
+	CircuitOpen bool   `json:"circuit_open,omitempty"`
+}
+
+type TCPStatus struct {
+	Name        string         `json:"name"`
+	Address     string         `json:"address"`
+	Healthy     bool           `json:"healthy"`
+	LatencyMs   *int64         `json:"latency_ms,omitempty"`
+	LastError   string         `json:"last_error,omitempty"`
+	CircuitOpen bool           `json:"circuit_open,omitempty"`
+	Families    []FamilyStatus `json:"families,omitempty"`
+	DependsOn   []string       `json:"depends_on,omitempty"`
 }
 
 type IncidentSummary struct {
-	ID         int64  `json:"id"`
-	EntityType string `json:"entity_type"`
-	EntityName string `json:"entity_name"`
-	Severity   string `json:"severity"`
-	Summary    string `json:"summary"`
-	StartedAt  string `json:"started_at"`
+	ID           int64  `json:"id"`
+	EntityType   string `json:"entity_type"`
+	EntityName   string `json:"entity_name"`
+	State        string `json:"state"`
+	Acknowledged bool   `json:"acknowledged"`
+	Flapping     bool   `json:"flapping,omitempty"`
+	FlapCount    int    `json:"flap_count,omitempty"`
+	Maintenance  bool   `json:"maintenance,omitempty"`
+	ParentID     int64  `json:"parent_id,omitempty"`
+	ImpactedBy   int64  `json:"impacted_by,omitempty"`
+	Manual       bool   `json:"manual,omitempty"`
+	ReportCount  int    `json:"report_count"`
+	Severity     string `json:"severity"`
+	Summary      string `json:"summary"`
+	StartedAt    string `json:"started_at"`
 }
 
 func (h *Handlers) Status(w http.ResponseWriter, r *http.Request) {
@@ -87,6 +158,9 @@ func (h *Handlers) Status(w http.ResponseWriter, r *http.Request) {
 				ps.LastError = c.ErrorCategory.String
 			}
 		}
+		ps.CircuitOpen = h.circuitOpen("rpc", name)
+		ps.Families = h.familyStatuses(ctx, "rpc", name)
+		ps.DependsOn = h.DependsOn["rpc/"+name]
 		resp.RPCProviders = append(resp.RPCProviders, ps)
 	}
 	for _, name := range h.DappNames {
@@ -101,24 +175,98 @@ func (h *Handlers) Status(w http.ResponseWriter, r *http.Request) {
 			if c.LatencyMs.Valid {
 				ds.LatencyMs = &c.LatencyMs.Int64
 			}
+			if c.ErrorCategory.Valid {
+				ds.LastError = c.ErrorCategory.String
+			}
+			steps, _ := h.Store.CheckSteps(ctx, c.ID)
+			for _, st := range steps {
+				ds.Steps = append(ds.Steps, StepStatus{
+					Name: st.Name, Success: st.Success, Status: st.Status, LatencyMs: st.LatencyMs, LastError: st.ErrorCategory, Detail: st.Detail,
+				})
+			}
 		}
+		ds.CircuitOpen = h.circuitOpen("dapp", name)
+		ds.Families = h.familyStatuses(ctx, "dapp", name)
+		ds.DependsOn = h.DependsOn["dapp/"+name]
 		resp.Dapps = append(resp.Dapps, ds)
 	}
+	for _, name := range h.TCPNames {
+		checks, _ := h.Store.RecentChecks(ctx, "tcp", name, 1)
+		ts := TCPStatus{Name: name}
+		if h.TCPAddrs != nil {
+			ts.Address = h.TCPAddrs[name]
+		}
+		if len(checks) > 0 {
+			c := checks[0]
+			ts.Healthy = c.Success
+			if c.LatencyMs.Valid {
+				ts.LatencyMs = &c.LatencyMs.Int64
+			}
+			if c.ErrorCategory.Valid {
+				ts.LastError = c.ErrorCategory.String
+			}
+		}
+		ts.CircuitOpen = h.circuitOpen("tcp", name)
+		ts.Families = h.familyStatuses(ctx, "tcp", name)
+		ts.DependsOn = h.DependsOn["tcp/"+name]
+		resp.TCPTargets = append(resp.TCPTargets, ts)
+	}
+	for _, name := range h.NodeNames {
+		checks, _ := h.Store.RecentChecks(ctx, "node", name, 1)
+		ns := ProviderStatus{Name: name}
+		if h.NodeURLs != nil {
+			ns.URL = h.NodeURLs[name]
+		}
+		if len(checks) > 0 {
+			c := checks[0]
+			ns.Healthy = c.Success
+			if c.LatencyMs.Valid {
+				ns.LatencyMs = &c.LatencyMs.Int64
+			}
+			if c.ErrorCategory.Valid {
+				ns.LastError = c.ErrorCategory.String
+			}
+		}
+		ns.CircuitOpen = h.circuitOpen("node", name)
+		ns.DependsOn = h.DependsOn["node/"+name]
+		resp.Nodes = append(resp.Nodes, ns)
+	}
 	openList, _ := h.Store.ListIncidents(ctx, store.IncidentStateOpen, 20)
 	for _, i := range openList {
-		resp.OpenIncidents = append(resp.OpenIncidents, IncidentSummary{
-			ID:         i.ID,
-			EntityType: i.EntityType,
-			EntityName: i.EntityName,
-			Severity:   i.Severity,
-			Summary:    i.Summary,
-			StartedAt:  i.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
-		})
+		resp.OpenIncidents = append(resp.OpenIncidents, incidentSummary(i))
+	}
+	if m := h.maintenance(ctx, false); len(m) > 0 {
+		resp.Maintenance = m
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+func (h *Handlers) familyStatuses(ctx context.Context, entityType, name string) []FamilyStatus {
+	var out []FamilyStatus
+	for _, fam := range h.IPFamilies[entityType+"/"+name] {
+		entity := config.FamilyEntityName(name, fam)
+		fs := FamilyStatus{Family: fam, CircuitOpen: h.circuitOpen(entityType, entity)}
+		checks, _ := h.Store.RecentChecks(ctx, entityType, entity, 1)
+		if len(checks) > 0 {
+			c := checks[0]
+			fs.Healthy = c.Success
+			if c.LatencyMs.Valid {
+				fs.LatencyMs = &c.LatencyMs.Int64
+			}
+			if c.ErrorCategory.Valid {
+				fs.LastError = c.ErrorCategory.String
+			}
+		}
+		out = append(out, fs)
+	}
+	return out
+}
+
+func (h *Handlers) circuitOpen(entityType, name string) bool {
+	return h.Circuits != nil && h.Circuits.CircuitOpen(entityType, name)
+}
+
 func (h *Handlers) ListIncidents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -138,21 +286,30 @@ func (h *Handlers) ListIncidents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	type incidentRow struct {
-		ID         int64   `json:"id"`
-		EntityType string  `json:"entity_type"`
-		EntityName string  `json:"entity_name"`
-		EntityURL  string  `json:"entity_url"`
-		State      string  `json:"state"`
-		Severity   string  `json:"severity"`
-		Summary    string  `json:"summary"`
-		StartedAt  string  `json:"started_at"`
-		EndedAt    *string `json:"ended_at,omitempty"`
+		ID           int64   `json:"id"`
+		EntityType   string  `json:"entity_type"`
+		EntityName   string  `json:"entity_name"`
+		EntityURL    string  `json:"entity_url"`
+		State        string  `json:"state"`
+		Acknowledged bool    `json:"acknowledged"`
+		Flapping     bool    `json:"flapping,omitempty"`
+		FlapCount    int     `json:"flap_count,omitempty"`
+		Maintenance  bool    `json:"maintenance,omitempty"`
+		ParentID     int64   `json:"parent_id,omitempty"`
+		ImpactedBy   int64   `json:"impacted_by,omitempty"`
+		Manual       bool    `json:"manual,omitempty"`
+		ReportCount  int     `json:"report_count"`
+		Severity     string  `json:"severity"`
+		Summary      string  `json:"summary"`
+		StartedAt    string  `json:"started_at"`
+		EndedAt      *string `json:"ended_at,omitempty"`
 	}
 	out := make([]incidentRow, 0, len(list))
 	for _, i := range list {
 		row := incidentRow{
 			ID: i.ID, EntityType: i.EntityType, EntityName: i.EntityName, EntityURL: i.EntityURL,
-			State: i.State, Severity: i.Severity, Summary: i.Summary,
+			State: i.State, Acknowledged: i.Acknowledged, Flapping: i.Flapping, FlapCount: i.FlapCount,
+			Maintenance: i.Maintenance, ParentID: i.ParentID, ImpactedBy: i.ImpactedBy, Manual: i.Manual, ReportCount: i.ReportCount, Severity: i.Severity, Summary: i.Summary,
 			StartedAt: i.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
 		if i.EndedAt != nil {
@@ -181,38 +338,79 @@ func (h *Handlers) GetIncident(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "not found", http.StatusNotFound)
 		return
 	}
-	updates, _ := h.Store.IncidentUpdates(r.Context(), id)
-	type incidentDetail struct {
-		ID         int64  `json:"id"`
-		EntityType string `json:"entity_type"`
-		EntityName string `json:"entity_name"`
-		EntityURL  string `json:"entity_url"`
-		State      string `json:"state"`
-		Severity   string `json:"severity"`
-		Summary    string `json:"summary"`
-		StartedAt  string `json:"started_at"`
-		EndedAt    string `json:"ended_at,omitempty"`
-		Updates    []struct {
-			Message   string `json:"message"`
-			CreatedAt string `json:"created_at"`
-		} `json:"updates"`
-	}
-	detail := incidentDetail{
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.incidentDetail(r.Context(), inc))
+}
+
+type IncidentDetail struct {
+	ID           int64             `json:"id"`
+	EntityType   string            `json:"entity_type"`
+	EntityName   string            `json:"entity_name"`
+	EntityURL    string            `json:"entity_url"`
+	State        string            `json:"state"`
+	Acknowledged bool              `json:"acknowledged"`
+	Flapping     bool              `json:"flapping,omitempty"`
+	FlapCount    int               `json:"flap_count,omitempty"`
+	Maintenance  bool              `json:"maintenance,omitempty"`
+	ParentID     int64             `json:"parent_id,omitempty"`
+	ImpactedBy   int64             `json:"impacted_by,omitempty"`
+	Manual       bool              `json:"manual,omitempty"`
+	ReportCount  int               `json:"report_count"`
+	Severity     string            `json:"severity"`
+	Summary      string            `json:"summary"`
+	StartedAt    string            `json:"started_at"`
+	EndedAt      string            `json:"ended_at,omitempty"`
+	Children     []IncidentSummary `json:"children,omitempty"`
+	Updates      []IncidentUpdate  `json:"updates"`
+}
+
+type IncidentUpdate struct {
+	State     string `json:"state,omitempty"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (h *Handlers) incidentDetail(ctx context.Context, inc *store.Incident) IncidentDetail {
+	detail := IncidentDetail{
 		ID: inc.ID, EntityType: inc.EntityType, EntityName: inc.EntityName, EntityURL: inc.EntityURL,
-		State: inc.State, Severity: inc.Severity, Summary: inc.Summary,
+		State: inc.State, Acknowledged: inc.Acknowledged, Flapping: inc.Flapping, FlapCount: inc.FlapCount,
+		Maintenance: inc.Maintenance, ParentID: inc.ParentID, ImpactedBy: inc.ImpactedBy, Manual: inc.Manual, ReportCount: inc.ReportCount, Severity: inc.Severity, Summary: inc.Summary,
 		StartedAt: inc.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 	if inc.EndedAt != nil {
 		detail.EndedAt = inc.EndedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
+	children, _ := h.Store.ChildIncidents(ctx, inc.ID)
+	for _, c := range children {
+		detail.Children = append(detail.Children, incidentSummary(c))
+	}
+	updates, _ := h.Store.IncidentUpdates(ctx, inc.ID)
 	for _, u := range updates {
-		detail.Updates = append(detail.Updates, struct {
-			Message   string `json:"message"`
-			CreatedAt string `json:"created_at"`
-		}{u.Message, u.CreatedAt.Format("2006-01-02T15:04:05Z07:00")})
+		detail.Updates = append(detail.Updates, IncidentUpdate{
+			State: u.State, Message: u.Message, CreatedAt: u.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	return detail
+}
+
+func incidentSummary(i store.Incident) IncidentSummary {
+	return IncidentSummary{
+		ID:           i.ID,
+		EntityType:   i.EntityType,
+		EntityName:   i.EntityName,
+		State:        i.State,
+		Acknowledged: i.Acknowledged,
+		Flapping:     i.Flapping,
+		FlapCount:    i.FlapCount,
+		Maintenance:  i.Maintenance,
+		ParentID:     i.ParentID,
+		ImpactedBy:   i.ImpactedBy,
+		Manual:       i.Manual,
+		ReportCount:  i.ReportCount,
+		Severity:     i.Severity,
+		Summary:      i.Summary,
+		StartedAt:    i.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(detail)
 }
 
 type ReportRequest struct {
@@ -261,15 +459,26 @@ func (h *Handlers) Report(w http.ResponseWriter, r *http.Request) {
 	if r.UserAgent() != "" {
 		rep.UserAgent = r.UserAgent()
 	}
-	id, err := h.Store.InsertReport(r.Context(), rep)
+	rep.Reporter = store.ReporterKey("api", clientAddr(r), r.UserAgent())
+	var id int64
+	var err error
+	if h.Engine != nil {
+		id, err = h.Engine.SubmitReport(r.Context(), rep)
+	} else {
+		id, err = h.Store.InsertReport(r.Context(), rep)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	metrics.IncReportsTotal()
+	resp := map[string]int64{"id": id}
+	if rep.IncidentID.Valid {
+		resp["incident_id"] = rep.IncidentID.Int64
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(map[string]int64{"id": id})
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (h *Handlers) ListReports(w http.ResponseWriter, r *http.Request) {
@@ -295,19 +504,30 @@ func (h *Handlers) ListReports(w http.ResponseWriter, r *http.Request) {
 		Device      string `json:"device"`
 		Region      string `json:"region"`
 		Description string `json:"description"`
+		IncidentID  int64  `json:"incident_id,omitempty"`
 		CreatedAt   string `json:"created_at"`
 	}
 	out := make([]redactedReport, 0, len(list))
 	for _, r := range list {
 		out = append(out, redactedReport{
 			ID: r.ID, IssueType: r.IssueType, Device: r.Device, Region: r.Region,
-			Description: r.Description, CreatedAt: r.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Description: r.Description, IncidentID: r.IncidentID.Int64, CreatedAt: r.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		})
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(out)
 }
 
+// clientAddr is the request's peer address without the port. Forwarding
+// headers are ignored since any client can set them.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func trunc(s string, max int) string {
 	if len(s) <= max {
 		return s
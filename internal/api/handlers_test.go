@@ -3,15 +3,20 @@ package api
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorusys/aptos-guardian/internal/config"
 	"github.com/gorusys/aptos-guardian/internal/incidents"
+	"github.com/gorusys/aptos-guardian/internal/monitor"
 	"github.com/gorusys/aptos-guardian/internal/store"
 )
 
@@ -75,6 +80,195 @@ func TestStatus(t *testing.T) {
 	}
 }
 
+func TestStatus_TCPTargets(t *testing.T) {
+	h := setupHandlers(t)
+	h.TCPNames = []string{"fullnode-p2p"}
+	h.TCPAddrs = map[string]string{"fullnode-p2p": "fullnode.example.com:6182"}
+	_ = h.Store.InsertCheck(context.Background(), "tcp", "fullnode-p2p", false, nil, "conn_refused")
+	req := httptest.NewRequest(http.MethodGet, "/v1/status", nil)
+	rec := httptest.NewRecorder()
+	h.Status(rec, req)
+	var resp StatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.TCPTargets) != 1 {
+		t.Fatalf("tcp_targets len = %d", len(resp.TCPTargets))
+	}
+	if resp.TCPTargets[0].Healthy || resp.TCPTargets[0].LastError != "conn_refused" {
+		t.Errorf("tcp target = %+v", resp.TCPTargets[0])
+	}
+}
+
+func TestStatus_DependsOn(t *testing.T) {
+	h := setupHandlers(t)
+	h.DependsOn = map[string][]string{"dapp/" + h.DappNames[0]: {"rpc/" + h.RPCNames[0]}}
+	req := httptest.NewRequest(http.MethodGet, "/v1/status", nil)
+	rec := httptest.NewRecorder()
+	h.Status(rec, req)
+	var resp StatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := resp.Dapps[0].DependsOn; len(got) != 1 || got[0] != "rpc/"+h.RPCNames[0] {
+		t.Errorf("dapp depends_on = %v", got)
+	}
+	if len(resp.RPCProviders[0].DependsOn) != 0 {
+		t.Errorf("rpc depends_on = %v", resp.RPCProviders[0].DependsOn)
+	}
+}
+
+func TestStatus_IPFamilies(t *testing.T) {
+	h := setupHandlers(t)
+	name := h.RPCNames[0]
+	h.IPFamilies = map[string][]string{"rpc/" + name: {"ipv4", "ipv6"}}
+	ctx := context.Background()
+	_ = h.Store.InsertCheck(ctx, "rpc", name+"@ipv4", true, nil, "")
+	_ = h.Store.InsertCheck(ctx, "rpc", name+"@ipv6", false, nil, "dns")
+	req := httptest.NewRequest(http.MethodGet, "/v1/status", nil)
+	rec := httptest.NewRecorder()
+	h.Status(rec, req)
+	var resp StatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	fams := resp.RPCProviders[0].Families
+	if len(fams) != 2 {
+		t.Fatalf("families = %+v", fams)
+	}
+	if !fams[0].Healthy || fams[1].Healthy || fams[1].LastError != "dns" {
+		t.Errorf("families = %+v", fams)
+	}
+}
+
+type fakeCircuits map[string]bool
+
+func (f fakeCircuits) CircuitOpen(entityType, name string) bool { return f[entityType+"/"+name] }
+
+func TestStatus_CircuitOpen(t *testing.T) {
+	h := setupHandlers(t)
+	name := h.RPCNames[0]
+	h.Circuits = fakeCircuits{"rpc/" + name: true}
+	req := httptest.NewRequest(http.MethodGet, "/v1/status", nil)
+	rec := httptest.NewRecorder()
+	h.Status(rec, req)
+	var resp StatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.RPCProviders[0].CircuitOpen {
+		t.Errorf("%s: circuit_open = false", name)
+	}
+	if len(resp.RPCProviders) > 1 && resp.RPCProviders[1].CircuitOpen {
+		t.Errorf("%s: circuit_open = true", resp.RPCProviders[1].Name)
+	}
+}
+
+type fakeBudgets []monitor.BudgetStatus
+
+func (f fakeBudgets) RequestBudgets() []monitor.BudgetStatus { return f }
+
+func TestAdminBudgets(t *testing.T) {
+	h := setupHandlers(t)
+	h.Budgets = fakeBudgets{{Provider: "premium", Daily: 1000, Used: 900, Remaining: 100, Throttled: true, MinInterval: 90 * time.Second}}
+	mux := Router(h, "", nil, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/budgets", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("no admin token: status = %d", rec.Code)
+	}
+
+	h.AdminToken = "s3cret"
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/budgets", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/budgets", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp struct {
+		Providers []BudgetStatus `json:"providers"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Providers) != 1 {
+		t.Fatalf("providers = %+v", resp.Providers)
+	}
+	b := resp.Providers[0]
+	if b.Remaining == nil || *b.Remaining != 100 || !b.Throttled || b.MinIntervalSecs != 90 {
+		t.Errorf("budget = %+v", b)
+	}
+}
+
+func TestAdminIncidentState(t *testing.T) {
+	h := setupHandlers(t)
+	h.AdminToken = "s3cret"
+	mux := Router(h, "", nil, "")
+	ctx := context.Background()
+	id, _ := h.Store.OpenIncident(ctx, "rpc", "aptoslabs", "https://x", "CRIT", "down")
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	base := "/v1/admin/incidents/" + strconv.FormatInt(id, 10)
+
+	if rec := post(base+"/state", `{"state":"done"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid state: status = %d", rec.Code)
+	}
+	if rec := post("/v1/admin/incidents/999/state", `{"state":"identified"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown incident: status = %d", rec.Code)
+	}
+	rec := post(base+"/state", `{"state":"identified","message":"Upstream load balancer."}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("state: status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var detail IncidentDetail
+	if err := json.NewDecoder(rec.Body).Decode(&detail); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if detail.State != store.IncidentStateIdentified || len(detail.Updates) != 1 || detail.Updates[0].Message != "Upstream load balancer." {
+		t.Errorf("detail = %+v", detail)
+	}
+	if rec := post(base+"/ack", `{"by":`); rec.Code != http.StatusBadRequest {
+		t.Errorf("ack with invalid json: status = %d", rec.Code)
+	}
+	// An empty chunked body has no Content-Length and is no actor.
+	req := httptest.NewRequest(http.MethodPost, base+"/ack", io.NopCloser(strings.NewReader("")))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	if req.ContentLength != -1 {
+		t.Fatalf("content length = %d, want unknown", req.ContentLength)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ack: status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&detail)
+	if !detail.Acknowledged {
+		t.Error("acknowledged = false")
+	}
+	if rec := post(base+"/state", `{"state":"resolved"}`); rec.Code != http.StatusOK {
+		t.Errorf("resolve: status = %d", rec.Code)
+	}
+	if rec := post(base+"/state", `{"state":"monitoring"}`); rec.Code != http.StatusConflict {
+		t.Errorf("after resolve: status = %d", rec.Code)
+	}
+}
+
 func TestListIncidents(t *testing.T) {
 	h := setupHandlers(t)
 	req := httptest.NewRequest(http.MethodGet, "/v1/incidents?state=open&limit=10", nil)
@@ -104,6 +298,38 @@ func TestGetIncident(t *testing.T) {
 	}
 }
 
+func TestGetIncident_Children(t *testing.T) {
+	h := setupHandlers(t)
+	ctx := context.Background()
+	parentID, _ := h.Store.OpenIncident(ctx, incidents.ParentEntityType, incidents.ParentEntityName, "", store.SeverityCrit, "network-wide")
+	childID, _ := h.Store.OpenIncident(ctx, "rpc", "test", "https://x.com", store.SeverityCrit, "down")
+	if err := h.Store.SetIncidentParent(ctx, childID, parentID); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/incidents/"+strconv.FormatInt(parentID, 10), nil)
+	rec := httptest.NewRecorder()
+	h.GetIncident(rec, req)
+	var parent IncidentDetail
+	if err := json.NewDecoder(rec.Body).Decode(&parent); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(parent.Children) != 1 || parent.Children[0].ID != childID || parent.Children[0].ParentID != parentID {
+		t.Errorf("children = %+v", parent.Children)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/incidents/"+strconv.FormatInt(childID, 10), nil)
+	rec = httptest.NewRecorder()
+	h.GetIncident(rec, req)
+	var child IncidentDetail
+	if err := json.NewDecoder(rec.Body).Decode(&child); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if child.ParentID != parentID || len(child.Children) != 0 {
+		t.Errorf("child = %+v", child)
+	}
+}
+
 func TestReport(t *testing.T) {
 	h := setupHandlers(t)
 	body := bytes.NewBufferString(`{"issue_type":"rpc_down","description":"cannot connect"}`)
@@ -123,6 +349,60 @@ func TestReport(t *testing.T) {
 	}
 }
 
+func TestReport_LinksIncident(t *testing.T) {
+	h := setupHandlers(t)
+	ctx := context.Background()
+	id, _ := h.Store.OpenIncident(ctx, "dapp", "explorer", "https://explorer.aptoslabs.com", store.SeverityCrit, "down")
+	for range 2 {
+		body := bytes.NewBufferString(`{"issue_type":"dapp_down","url":"https://explorer.aptoslabs.com/txn/0x1"}`)
+		rec := httptest.NewRecorder()
+		h.Report(rec, httptest.NewRequest(http.MethodPost, "/v1/report", body))
+		var out map[string]int64
+		if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if out["incident_id"] != id {
+			t.Errorf("incident_id = %d, want %d", out["incident_id"], id)
+		}
+	}
+	rec := httptest.NewRecorder()
+	h.GetIncident(rec, httptest.NewRequest(http.MethodGet, "/v1/incidents/"+strconv.FormatInt(id, 10), nil))
+	var detail IncidentDetail
+	if err := json.NewDecoder(rec.Body).Decode(&detail); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if detail.ReportCount != 2 {
+		t.Errorf("report_count = %d", detail.ReportCount)
+	}
+}
+
+func TestReport_SpikeCountsDistinctReporters(t *testing.T) {
+	h := setupHandlers(t)
+	ctx := context.Background()
+	post := func(addr string) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/report", bytes.NewBufferString(`{"issue_type":"wallet_sign"}`))
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		h.Report(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d", rec.Code)
+		}
+	}
+	// The example config opens an incident at 10 reports in the window.
+	for range 12 {
+		post("192.0.2.1:4000")
+	}
+	if has, _, _ := h.Store.HasOpenIncident(ctx, incidents.ReportEntityType, "issue:wallet_sign"); has {
+		t.Fatal("one client repeating a report should not open an incident")
+	}
+	for i := 2; i <= 10; i++ {
+		post("192.0.2." + strconv.Itoa(i) + ":4000")
+	}
+	if has, _, _ := h.Store.HasOpenIncident(ctx, incidents.ReportEntityType, "issue:wallet_sign"); !has {
+		t.Error("10 distinct reporters should open an incident")
+	}
+}
+
 func TestReport_BadRequest(t *testing.T) {
 	h := setupHandlers(t)
 	body := bytes.NewBufferString(`{}`)
@@ -174,3 +454,232 @@ func TestStaticHandler(t *testing.T) {
 	}
 	t.Skip("web dir not found (run from repo root)")
 }
+
+func TestAdminMaintenance(t *testing.T) {
+	h := setupHandlers(t)
+	h.AdminToken = "s3cret"
+	mux := Router(h, "", nil, "")
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := do(http.MethodPost, "/v1/admin/maintenance", `{"entity":"rpc/aptoslabs"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing ends_at: status = %d", rec.Code)
+	}
+	ends := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	rec := do(http.MethodPost, "/v1/admin/maintenance", `{"entity":"rpc/aptoslabs","ends_at":"`+ends+`","reason":"Upgrade","by":"ops"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var created MaintenanceStatus
+	_ = json.NewDecoder(rec.Body).Decode(&created)
+	if created.ID == 0 || !created.Active || created.Mode != "suppress" {
+		t.Errorf("created = %+v", created)
+	}
+
+	statusRec := httptest.NewRecorder()
+	mux.ServeHTTP(statusRec, httptest.NewRequest(http.MethodGet, "/v1/status", nil))
+	var status StatusResponse
+	_ = json.NewDecoder(statusRec.Body).Decode(&status)
+	if len(status.Maintenance) != 1 || status.Maintenance[0].Reason != "Upgrade" || status.Maintenance[0].CreatedBy != "" {
+		t.Errorf("status maintenance = %+v", status.Maintenance)
+	}
+
+	path := "/v1/admin/maintenance/" + strconv.FormatInt(created.ID, 10)
+	if rec := do(http.MethodDelete, path, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("delete again: status = %d", rec.Code)
+	}
+
+	// A valid window the store fails to save is a server error.
+	_ = h.Store.Close()
+	if rec := do(http.MethodPost, "/v1/admin/maintenance", `{"all":true,"ends_at":"`+ends+`"}`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("store failure: status = %d", rec.Code)
+	}
+}
+
+func TestSLO(t *testing.T) {
+	h := setupHandlers(t)
+	ctx := context.Background()
+	fast, slow := int64(200), int64(1000)
+	_ = h.Store.InsertCheck(ctx, "rpc", "aptoslabs", true, &fast, "")
+	_ = h.Store.InsertCheck(ctx, "rpc", "aptoslabs", true, &slow, "")
+	req := httptest.NewRequest(http.MethodGet, "/v1/slo", nil)
+	rec := httptest.NewRecorder()
+	h.SLO(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var out []SLOStatus
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("len = %d", len(out))
+	}
+	if out[0].Total != 2 || out[0].SLI != 100 || out[0].ErrorBudgetRemaining != 1 {
+		t.Errorf("availability = %+v", out[0])
+	}
+	if out[1].LatencyMS != 800 || out[1].Good != 1 || out[1].SLI != 50 {
+		t.Errorf("latency = %+v", out[1])
+	}
+	if _, ok := out[1].BurnRates["1h"]; !ok || len(out[1].BurnRates) != 4 {
+		t.Errorf("burn_rates = %v", out[1].BurnRates)
+	}
+}
+
+func TestUptime(t *testing.T) {
+	h := setupHandlers(t)
+	ctx := context.Background()
+	name := h.RPCNames[0]
+	_ = h.Store.InsertCheck(ctx, "rpc", name, true, nil, "")
+	_ = h.Store.InsertCheck(ctx, "rpc", name, false, nil, "timeout")
+	if err := h.Store.RollupUptime(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"entity=rpc/unknown", "entity=rpc/" + name + "&range=1y"} {
+		rec := httptest.NewRecorder()
+		h.Uptime(rec, httptest.NewRequest(http.MethodGet, "/v1/uptime?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d", q, rec.Code)
+		}
+	}
+	for _, rng := range []string{"24h", "90d"} {
+		rec := httptest.NewRecorder()
+		h.Uptime(rec, httptest.NewRequest(http.MethodGet, "/v1/uptime?entity=rpc/"+name+"&range="+rng, nil))
+		var out UptimeResponse
+		if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(out.Buckets) != 1 || out.Total != 2 || out.UptimePct == nil || *out.UptimePct != 50 {
+			t.Errorf("%s: %+v", rng, out)
+		}
+		if out.Buckets[0].Errors["timeout"] != 1 {
+			t.Errorf("%s: errors = %v", rng, out.Buckets[0].Errors)
+		}
+	}
+}
+
+func TestPostmortem(t *testing.T) {
+	h := setupHandlers(t)
+	ctx := context.Background()
+	_ = h.Store.InsertCheck(ctx, "rpc", "aptoslabs", false, nil, "timeout")
+	id, _ := h.Store.OpenIncident(ctx, "rpc", "aptoslabs", "https://x.com", store.SeverityWarn, "RPC unreachable")
+	_ = h.Store.AddSeverityUpdate(ctx, id, store.SeverityWarn, store.SeverityCrit, "Severity raised from WARN to CRIT: RPC | still unreachable")
+	_ = h.Store.SetIncidentSeverity(ctx, id, store.SeverityCrit)
+	downstream, _ := h.Store.OpenIncident(ctx, "dapp", "explorer", "", store.SeverityWarn, "explorer down")
+	_ = h.Store.SetIncidentImpactedBy(ctx, downstream, id)
+	_, _ = h.Store.InsertReport(ctx, &store.Report{IssueType: "rpc_down", Region: "EU", IncidentID: sql.NullInt64{Int64: id, Valid: true}})
+	lat := int64(120)
+	_ = h.Store.InsertCheck(ctx, "rpc", "aptoslabs", true, &lat, "")
+	_ = h.Store.CloseIncident(ctx, id, "Recovered")
+
+	path := "/v1/incidents/" + strconv.FormatInt(id, 10) + "/postmortem"
+	rec := httptest.NewRecorder()
+	Router(h, "", nil, "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?format=json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var pm Postmortem
+	if err := json.NewDecoder(rec.Body).Decode(&pm); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if pm.Incident.ID != id || pm.Ongoing || pm.InitialSeverity != store.SeverityWarn {
+		t.Errorf("incident = %+v, initial %s", pm.Incident, pm.InitialSeverity)
+	}
+	if len(pm.SeverityChanges) != 1 || pm.SeverityChanges[0].To != store.SeverityCrit {
+		t.Errorf("severity changes = %+v", pm.SeverityChanges)
+	}
+	if len(pm.Impacted) != 1 || pm.Impacted[0].ID != downstream {
+		t.Errorf("impacted = %+v", pm.Impacted)
+	}
+	if len(pm.Reports) != 1 || len(pm.ChecksAtStart) != 2 || len(pm.ChecksAtEnd) != 0 {
+		t.Errorf("reports = %d, checks = %d/%d", len(pm.Reports), len(pm.ChecksAtStart), len(pm.ChecksAtEnd))
+	}
+	kinds := map[string]int{}
+	for _, e := range pm.Timeline {
+		kinds[e.Kind]++
+	}
+	if kinds["update"] != 1 || kinds["report"] != 1 || kinds["impacted"] != 1 || kinds["check"] != 2 {
+		t.Errorf("timeline = %+v", pm.Timeline)
+	}
+
+	rec = httptest.NewRecorder()
+	Router(h, "", nil, "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	md := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Errorf("content type = %q", ct)
+	}
+	for _, want := range []string{"# Incident #", "- **Severity:** WARN → CRIT", "## Affected entities", "impacted", "RPC \\| still unreachable", "| ok | 120 ms |", "## User reports"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.Postmortem(rec, httptest.NewRequest(http.MethodGet, path+"?format=pdf", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad format: status = %d", rec.Code)
+	}
+}
+
+func TestAdminManualIncident(t *testing.T) {
+	h := setupHandlers(t)
+	h.AdminToken = "s3cret"
+	mux := Router(h, "", nil, "")
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for body, code := range map[string]int{
+		`{"entity":"rpc/unknown","summary":"down"}`:                   http.StatusBadRequest,
+		`{"entity":"announcement/x","severity":"info","summary":"x"}`: http.StatusBadRequest,
+		`{"entity":"announcement/x"}`:                                 http.StatusBadRequest,
+	} {
+		if rec := post("/v1/admin/incidents", body); rec.Code != code {
+			t.Errorf("%s: status = %d", body, rec.Code)
+		}
+	}
+	rec := post("/v1/admin/incidents", `{"entity":"announcement/Petra wallet","severity":"crit","summary":"Signing fails.","by":"ops"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var detail IncidentDetail
+	_ = json.NewDecoder(rec.Body).Decode(&detail)
+	if !detail.Manual || detail.EntityType != "announcement" || detail.EntityName != "Petra wallet" || detail.Severity != store.SeverityCrit {
+		t.Errorf("detail = %+v", detail)
+	}
+	if rec := post("/v1/admin/incidents", `{"entity":"announcement/Petra wallet","summary":"again"}`); rec.Code != http.StatusConflict {
+		t.Errorf("duplicate: status = %d", rec.Code)
+	}
+
+	base := "/v1/admin/incidents/" + strconv.FormatInt(detail.ID, 10)
+	if rec := post(base+"/update", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty update: status = %d", rec.Code)
+	}
+	if rec := post(base+"/update", `{"message":"Fix deployed by the wallet team."}`); rec.Code != http.StatusOK {
+		t.Errorf("update: status = %d", rec.Code)
+	}
+	rec = post(base+"/severity", `{"severity":"WARN","message":"Most users recovered."}`)
+	_ = json.NewDecoder(rec.Body).Decode(&detail)
+	if rec.Code != http.StatusOK || detail.Severity != store.SeverityWarn {
+		t.Errorf("severity: status = %d, severity %s", rec.Code, detail.Severity)
+	}
+	rec = post(base+"/resolve", `{"message":"Wallet update released.","by":"ops"}`)
+	_ = json.NewDecoder(rec.Body).Decode(&detail)
+	if rec.Code != http.StatusOK || detail.State != store.IncidentStateResolved || len(detail.Updates) != 4 {
+		t.Errorf("resolve: status = %d, detail = %+v", rec.Code, detail)
+	}
+	if rec := post(base+"/resolve", `{}`); rec.Code != http.StatusConflict {
+		t.Errorf("resolve twice: status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/incidents"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+type MaintenanceStatus struct {
+	ID        int64  `json:"id,omitempty"`
+	Entity    string `json:"entity,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	All       bool   `json:"all,omitempty"`
+	StartsAt  string `json:"starts_at"`
+	EndsAt    string `json:"ends_at"`
+	Reason    string `json:"reason,omitempty"`
+	Mode      string `json:"mode"`
+	Active    bool   `json:"active"`
+	CreatedBy string `json:"created_by,omitempty"`
+}
+
+// MaintenanceRequest creates a window. StartsAt defaults to now.
+type MaintenanceRequest struct {
+	Entity   string     `json:"entity"`
+	Tag      string     `json:"tag"`
+	All      bool       `json:"all"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   time.Time  `json:"ends_at"`
+	Reason   string     `json:"reason"`
+	Mode     string     `json:"mode"`
+	By       string     `json:"by"`
+}
+
+// maintenance lists active and upcoming windows; admin adds who created them.
+func (h *Handlers) maintenance(ctx context.Context, admin bool) []MaintenanceStatus {
+	out := []MaintenanceStatus{}
+	if h.Engine == nil {
+		return out
+	}
+	windows, _ := h.Engine.Maintenances(ctx)
+	now := time.Now()
+	for _, m := range windows {
+		ms := maintenanceStatus(&m, now)
+		if !admin {
+			ms.CreatedBy = ""
+		}
+		out = append(out, ms)
+	}
+	return out
+}
+
+func maintenanceStatus(m *store.Maintenance, now time.Time) MaintenanceStatus {
+	return MaintenanceStatus{
+		ID: m.ID, Entity: m.Entity, Tag: m.Tag, All: m.All,
+		StartsAt: m.StartsAt.UTC().Format(time.RFC3339), EndsAt: m.EndsAt.UTC().Format(time.RFC3339),
+		Reason: m.Reason, Mode: m.Mode, Active: m.Active(now), CreatedBy: m.CreatedBy,
+	}
+}
+
+// AdminMaintenance serves GET and POST /v1/admin/maintenance and
+// DELETE /v1/admin/maintenance/{id}.
+func (h *Handlers) AdminMaintenance(w http.ResponseWriter, r *http.Request) {
+	if h.Engine == nil {
+		http.Error(w, "incident engine unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	idStr := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/admin/maintenance"), "/")
+	if idStr != "" {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "invalid maintenance id", http.StatusBadRequest)
+			return
+		}
+		switch err := h.Engine.CancelMaintenance(r.Context(), id); {
+		case errors.Is(err, incidents.ErrMaintenanceNotFound):
+			http.Error(w, "not found", http.StatusNotFound)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"maintenance": h.maintenance(r.Context(), true)})
+	case http.MethodPost:
+		var req MaintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		starts := time.Now().UTC()
+		if req.StartsAt != nil {
+			starts = *req.StartsAt
+		}
+		m, err := h.Engine.ScheduleMaintenance(r.Context(), store.Maintenance{
+			Entity: strings.TrimSpace(req.Entity), Tag: strings.TrimSpace(req.Tag), All: req.All,
+			StartsAt: starts, EndsAt: req.EndsAt, Reason: trunc(req.Reason, maxIncidentMessage),
+			Mode: req.Mode, CreatedBy: trunc(req.By, maxIncidentActor),
+		})
+		switch {
+		case errors.Is(err, incidents.ErrInvalidMaintenance):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(maintenanceStatus(m, time.Now()))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,338 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// postmortemCheckMargin is how far before and after the start and end of an
+// incident its entity's checks are included.
+const postmortemCheckMargin = 10 * time.Minute
+
+type Postmortem struct {
+	Incident IncidentDetail `json:"incident"`
+	// DurationSecs runs to now while the incident is ongoing.
+	DurationSecs    int64            `json:"duration_secs"`
+	Ongoing         bool             `json:"ongoing,omitempty"`
+	InitialSeverity string           `json:"initial_severity"`
+	SeverityChanges []SeverityChange `json:"severity_changes"`
+	// Impacted lists incidents of dependent entities marked impacted by this one.
+	Impacted []IncidentSummary  `json:"impacted"`
+	Reports  []PostmortemReport `json:"reports"`
+	// ChecksAtStart and ChecksAtEnd cover postmortemCheckMargin around the
+	// start and the end (now while ongoing); a short incident has one window
+	// in ChecksAtStart.
+	ChecksAtStart []PostmortemCheck `json:"checks_at_start"`
+	ChecksAtEnd   []PostmortemCheck `json:"checks_at_end"`
+	// Timeline merges updates, reports, impacted incidents and check
+	// transitions, oldest first.
+	Timeline []TimelineEntry `json:"timeline"`
+}
+
+type SeverityChange struct {
+	At      string `json:"at"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+type PostmortemReport struct {
+	ID          int64  `json:"id"`
+	IssueType   string `json:"issue_type"`
+	Device      string `json:"device,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Description string `json:"description,omitempty"`
+	IncidentID  int64  `json:"incident_id"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type PostmortemCheck struct {
+	At            string `json:"at"`
+	Success       bool   `json:"success"`
+	LatencyMs     *int64 `json:"latency_ms,omitempty"`
+	ErrorCategory string `json:"error_category,omitempty"`
+}
+
+type TimelineEntry struct {
+	At string `json:"at"`
+	// Kind is update, report, impacted or check.
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+
+	at time.Time
+}
+
+// Postmortem serves GET /v1/incidents/{id}/postmortem?format=md|json.
+// Markdown is the default.
+func (h *Handlers) Postmortem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/incidents/"), "/postmortem")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid incident id", http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "md" && format != "json" {
+		http.Error(w, "format must be md or json", http.StatusBadRequest)
+		return
+	}
+	inc, err := h.Store.GetIncident(r.Context(), id)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	pm, err := h.postmortem(r.Context(), inc, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(pm)
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, _ = w.Write([]byte(pm.Markdown()))
+}
+
+func (h *Handlers) postmortem(ctx context.Context, inc *store.Incident, now time.Time) (*Postmortem, error) {
+	pm := &Postmortem{
+		Incident:        h.incidentDetail(ctx, inc),
+		InitialSeverity: inc.Severity,
+		SeverityChanges: []SeverityChange{},
+		Impacted:        []IncidentSummary{},
+		Reports:         []PostmortemReport{},
+		ChecksAtStart:   []PostmortemCheck{},
+		ChecksAtEnd:     []PostmortemCheck{},
+		Timeline:        []TimelineEntry{},
+	}
+	end := now
+	if inc.EndedAt != nil {
+		end = *inc.EndedAt
+	} else {
+		pm.Ongoing = true
+	}
+	pm.DurationSecs = int64(end.Sub(inc.StartedAt) / time.Second)
+	add := func(at time.Time, kind, text string) {
+		pm.Timeline = append(pm.Timeline, TimelineEntry{At: at.UTC().Format(time.RFC3339), Kind: kind, Text: text, at: at})
+	}
+
+	updates, err := h.Store.IncidentUpdates(ctx, inc.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range updates {
+		add(u.CreatedAt, "update", u.Message)
+		if u.ToSeverity != "" {
+			if len(pm.SeverityChanges) == 0 {
+				pm.InitialSeverity = u.FromSeverity
+			}
+			pm.SeverityChanges = append(pm.SeverityChanges, SeverityChange{
+				At: u.CreatedAt.UTC().Format(time.RFC3339), From: u.FromSeverity, To: u.ToSeverity, Message: u.Message,
+			})
+		}
+	}
+
+	impacted, err := h.Store.ImpactedIncidents(ctx, inc.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range impacted {
+		pm.Impacted = append(pm.Impacted, incidentSummary(i))
+		add(i.StartedAt, "impacted", fmt.Sprintf("#%d %s/%s impacted: %s", i.ID, i.EntityType, i.EntityName, i.Summary))
+	}
+
+	reports, err := h.Store.IncidentReports(ctx, inc.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reports {
+		pm.Reports = append(pm.Reports, PostmortemReport{
+			ID: r.ID, IssueType: r.IssueType, Device: r.Device, Region: r.Region, Description: r.Description,
+			IncidentID: r.IncidentID.Int64, CreatedAt: r.CreatedAt.UTC().Format(time.RFC3339),
+		})
+		add(r.CreatedAt, "report", "User report: "+r.IssueType+reportDetail(r))
+	}
+
+	checks := func(from, to time.Time) ([]PostmortemCheck, error) {
+		rows, err := h.Store.ChecksBetween(ctx, inc.EntityType, inc.EntityName, from, to)
+		if err != nil {
+			return nil, err
+		}
+		out := []PostmortemCheck{}
+		for i, c := range rows {
+			pc := PostmortemCheck{At: c.CreatedAt.UTC().Format(time.RFC3339), Success: c.Success, ErrorCategory: c.ErrorCategory.String}
+			if c.LatencyMs.Valid {
+				lat := c.LatencyMs.Int64
+				pc.LatencyMs = &lat
+			}
+			out = append(out, pc)
+			if i > 0 && c.Success == rows[i-1].Success {
+				continue
+			}
+			switch {
+			case c.Success:
+				add(c.CreatedAt, "check", "Check passed.")
+			case c.ErrorCategory.String != "":
+				add(c.CreatedAt, "check", "Check failed: "+c.ErrorCategory.String+".")
+			default:
+				add(c.CreatedAt, "check", "Check failed.")
+			}
+		}
+		return out, nil
+	}
+	if end.Sub(inc.StartedAt) <= 2*postmortemCheckMargin {
+		if pm.ChecksAtStart, err = checks(inc.StartedAt.Add(-postmortemCheckMargin), end.Add(postmortemCheckMargin)); err != nil {
+			return nil, err
+		}
+	} else {
+		if pm.ChecksAtStart, err = checks(inc.StartedAt.Add(-postmortemCheckMargin), inc.StartedAt.Add(postmortemCheckMargin)); err != nil {
+			return nil, err
+		}
+		if pm.ChecksAtEnd, err = checks(end.Add(-postmortemCheckMargin), end.Add(postmortemCheckMargin)); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(pm.Timeline, func(i, j int) bool { return pm.Timeline[i].at.Before(pm.Timeline[j].at) })
+	return pm, nil
+}
+
+func reportDetail(r store.Report) string {
+	var parts []string
+	for _, s := range []string{r.Device, r.Region} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	out := ""
+	if len(parts) > 0 {
+		out = " (" + strings.Join(parts, ", ") + ")"
+	}
+	if r.Description != "" {
+		out += ": " + r.Description
+	}
+	return out
+}
+
+// Markdown renders the postmortem for pasting into a postmortem document.
+func (pm *Postmortem) Markdown() string {
+	inc := pm.Incident
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Incident #%d: %s/%s\n\n", inc.ID, inc.EntityType, inc.EntityName)
+	fmt.Fprintf(&b, "%s\n\n", mdText(inc.Summary))
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- **Entity:** %s/%s", inc.EntityType, inc.EntityName)
+	if inc.EntityURL != "" {
+		fmt.Fprintf(&b, " (%s)", inc.EntityURL)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- **State:** %s\n", inc.State)
+	fmt.Fprintf(&b, "- **Started:** %s\n", inc.StartedAt)
+	if pm.Ongoing {
+		fmt.Fprintf(&b, "- **Ended:** ongoing\n")
+	} else {
+		fmt.Fprintf(&b, "- **Ended:** %s\n", inc.EndedAt)
+	}
+	fmt.Fprintf(&b, "- **Duration:** %s", time.Duration(pm.DurationSecs)*time.Second)
+	if pm.Ongoing {
+		b.WriteString(" so far")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- **Severity:** %s", pm.InitialSeverity)
+	for _, sc := range pm.SeverityChanges {
+		fmt.Fprintf(&b, " → %s", sc.To)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- **Users affected:** %d\n", len(pm.Reports))
+	if inc.ParentID != 0 {
+		fmt.Fprintf(&b, "- **Part of:** #%d\n", inc.ParentID)
+	}
+	if inc.ImpactedBy != 0 {
+		fmt.Fprintf(&b, "- **Impacted by:** #%d\n", inc.ImpactedBy)
+	}
+	if inc.Maintenance {
+		b.WriteString("- **During maintenance:** yes\n")
+	}
+
+	if len(pm.SeverityChanges) > 0 {
+		b.WriteString("\n## Severity changes\n\n| Time | From | To | Update |\n| --- | --- | --- | --- |\n")
+		for _, sc := range pm.SeverityChanges {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", sc.At, sc.From, sc.To, mdCell(sc.Message))
+		}
+	}
+	if len(inc.Children) > 0 || len(pm.Impacted) > 0 {
+		b.WriteString("\n## Affected entities\n\n")
+		for _, c := range inc.Children {
+			fmt.Fprintf(&b, "- #%d %s/%s (linked, %s, %s): %s\n", c.ID, c.EntityType, c.EntityName, c.Severity, c.State, mdText(c.Summary))
+		}
+		for _, c := range pm.Impacted {
+			fmt.Fprintf(&b, "- #%d %s/%s (impacted, %s, %s): %s\n", c.ID, c.EntityType, c.EntityName, c.Severity, c.State, mdText(c.Summary))
+		}
+	}
+
+	b.WriteString("\n## Timeline\n\n| Time (UTC) | Event | Details |\n| --- | --- | --- |\n")
+	for _, e := range pm.Timeline {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", e.At, e.Kind, mdCell(e.Text))
+	}
+
+	writeChecks := func(title string, checks []PostmortemCheck) {
+		if len(checks) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n| Time (UTC) | Result | Latency | Error |\n| --- | --- | --- | --- |\n", title)
+		for _, c := range checks {
+			result, lat := "fail", "—"
+			if c.Success {
+				result = "ok"
+			}
+			if c.LatencyMs != nil {
+				lat = fmt.Sprintf("%d ms", *c.LatencyMs)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", c.At, result, lat, orDash(c.ErrorCategory))
+		}
+	}
+	if len(pm.ChecksAtEnd) == 0 {
+		writeChecks("Checks", pm.ChecksAtStart)
+	} else {
+		writeChecks("Checks around the start", pm.ChecksAtStart)
+		writeChecks("Checks around the end", pm.ChecksAtEnd)
+	}
+
+	if len(pm.Reports) > 0 {
+		b.WriteString("\n## User reports\n\n| Time (UTC) | Issue | Device | Region | Description |\n| --- | --- | --- | --- | --- |\n")
+		for _, r := range pm.Reports {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", r.CreatedAt, mdCell(r.IssueType), mdCell(orDash(r.Device)), mdCell(orDash(r.Region)), mdCell(r.Description))
+		}
+	}
+	return b.String()
+}
+
+// mdCell makes text safe inside a Markdown table cell.
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// mdText keeps user-supplied text on one line.
+func mdText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
@@ -13,6 +13,13 @@ func Router(h *Handlers, metricsPath string, metricsHandler http.Handler, webRoo
 	mux.HandleFunc("/v1/incidents/", h.incidentIDRoute)
 	mux.HandleFunc("/v1/report", h.Report)
 	mux.HandleFunc("/v1/reports", h.ListReports)
+	mux.HandleFunc("/v1/slo", h.SLO)
+	mux.HandleFunc("/v1/uptime", h.Uptime)
+	mux.HandleFunc("/v1/admin/budgets", h.requireAdmin(h.AdminBudgets))
+	mux.HandleFunc("/v1/admin/incidents", h.requireAdmin(h.AdminCreateIncident))
+	mux.HandleFunc("/v1/admin/incidents/", h.requireAdmin(h.adminIncidentRoute))
+	mux.HandleFunc("/v1/admin/maintenance", h.requireAdmin(h.AdminMaintenance))
+	mux.HandleFunc("/v1/admin/maintenance/", h.requireAdmin(h.AdminMaintenance))
 	if metricsPath != "" && metricsHandler != nil {
 		mux.Handle(metricsPath, metricsHandler)
 	}
@@ -27,5 +34,9 @@ func (h *Handlers) incidentIDRoute(w http.ResponseWriter, r *http.Request) {
 		h.ListIncidents(w, r)
 		return
 	}
+	if strings.HasSuffix(r.URL.Path, "/postmortem") {
+		h.Postmortem(w, r)
+		return
+	}
 	h.GetIncident(w, r)
 }
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorusys/aptos-guardian/internal/incidents"
+)
+
+type SLOStatus struct {
+	Name       string  `json:"name"`
+	Entity     string  `json:"entity"`
+	Objective  float64 `json:"objective"`
+	WindowDays int     `json:"window_days"`
+	LatencyMS  int     `json:"latency_ms,omitempty"`
+	Good       int     `json:"good"`
+	Total      int     `json:"total"`
+	// SLI is the percentage of good checks over the window.
+	SLI float64 `json:"sli"`
+	// ErrorBudgetRemaining is 1 with the budget untouched and negative once
+	// the objective is missed.
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	// BurnRates maps windows ("5m", "30m", "1h", "6h") to burn rates.
+	BurnRates map[string]float64 `json:"burn_rates"`
+	// Alert is the severity of a burn-rate alert that currently holds.
+	Alert string `json:"alert,omitempty"`
+}
+
+// SLO serves GET /v1/slo: every configured SLO with its error budget.
+func (h *Handlers) SLO(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	out := []SLOStatus{}
+	if h.Engine != nil {
+		statuses, err := h.Engine.SLOStatuses(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, s := range statuses {
+			ss := SLOStatus{
+				Name: s.Name, Entity: s.Entity, Objective: s.Objective, WindowDays: s.WindowDays, LatencyMS: s.LatencyMS,
+				Good: s.Good, Total: s.Total, SLI: s.SLI, ErrorBudgetRemaining: s.BudgetRemaining,
+				BurnRates: make(map[string]float64, len(s.BurnRates)), Alert: s.Alert,
+			}
+			for i, win := range incidents.BurnWindows {
+				ss.BurnRates[incidents.BurnWindowLabel(win)] = s.BurnRates[i]
+			}
+			out = append(out, ss)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// uptimeRanges maps the range parameter of /v1/uptime to its length and the
+// rollup granularity it is served from.
+var uptimeRanges = map[string]struct {
+	length      time.Duration
+	granularity string
+}{
+	"24h": {24 * time.Hour, store.UptimeHourly},
+	"7d":  {7 * 24 * time.Hour, store.UptimeHourly},
+	"30d": {30 * 24 * time.Hour, store.UptimeDaily},
+	"90d": {90 * 24 * time.Hour, store.UptimeDaily},
+}
+
+type UptimeResponse struct {
+	Entity      string `json:"entity"`
+	Range       string `json:"range"`
+	Granularity string `json:"granularity"`
+	Success     int    `json:"success"`
+	Total       int    `json:"total"`
+	// UptimePct is null without any checks in the range.
+	UptimePct *float64 `json:"uptime_pct"`
+	// Buckets holds one entry per hour or day with checks, oldest first.
+	Buckets []UptimeBucket `json:"buckets"`
+}
+
+type UptimeBucket struct {
+	Start     string         `json:"start"`
+	Success   int            `json:"success"`
+	Total     int            `json:"total"`
+	UptimePct float64        `json:"uptime_pct"`
+	P50Ms     int64          `json:"p50_ms"`
+	P95Ms     int64          `json:"p95_ms"`
+	MaxMs     int64          `json:"max_ms"`
+	Errors    map[string]int `json:"errors,omitempty"`
+}
+
+// Uptime serves GET /v1/uptime?entity=<type>/<name>&range=24h|7d|30d|90d
+// from the hourly and daily rollups.
+func (h *Handlers) Uptime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	entity := r.URL.Query().Get("entity")
+	entityType, name, _ := strings.Cut(entity, "/")
+	if !h.knownEntity(entityType, name) {
+		http.Error(w, "unknown entity", http.StatusBadRequest)
+		return
+	}
+	rangeStr := r.URL.Query().Get("range")
+	if rangeStr == "" {
+		rangeStr = "24h"
+	}
+	rng, ok := uptimeRanges[rangeStr]
+	if !ok {
+		http.Error(w, "range must be 24h, 7d, 30d or 90d", http.StatusBadRequest)
+		return
+	}
+	// The range ends with the bucket in progress.
+	now := time.Now().UTC()
+	start := now.Truncate(time.Hour).Add(time.Hour - rng.length)
+	if rng.granularity == store.UptimeDaily {
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(24*time.Hour - rng.length)
+	}
+	buckets, err := h.Store.UptimeBuckets(r.Context(), entityType, name, rng.granularity, start)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	out := UptimeResponse{Entity: entity, Range: rangeStr, Granularity: rng.granularity, Buckets: []UptimeBucket{}}
+	for _, b := range buckets {
+		out.Success += b.Success
+		out.Total += b.Total
+		ub := UptimeBucket{
+			Start: b.Start.Format(time.RFC3339), Success: b.Success, Total: b.Total,
+			P50Ms: b.P50Ms, P95Ms: b.P95Ms, MaxMs: b.MaxMs, Errors: b.Errors,
+		}
+		if b.Total > 0 {
+			ub.UptimePct = 100 * float64(b.Success) / float64(b.Total)
+		}
+		out.Buckets = append(out.Buckets, ub)
+	}
+	if out.Total > 0 {
+		pct := 100 * float64(out.Success) / float64(out.Total)
+		out.UptimePct = &pct
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// knownEntity reports whether entityType/name is a monitored entity. A name
+// may carry an IP family suffix such as "@ipv6".
+func (h *Handlers) knownEntity(entityType, name string) bool {
+	base, _, _ := strings.Cut(name, "@")
+	switch entityType {
+	case "rpc":
+		return slices.Contains(h.RPCNames, base)
+	case "dapp":
+		return slices.Contains(h.DappNames, base)
+	case "tcp":
+		return slices.Contains(h.TCPNames, base)
+	case "node":
+		return slices.Contains(h.NodeNames, base)
+	}
+	return false
+}
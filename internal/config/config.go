@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -23,13 +27,18 @@ func (d *durationMs) UnmarshalYAML(value *yaml.Node) error {
 func (d durationMs) Duration() time.Duration { return time.Duration(d) }
 
 type Config struct {
-	Interval     time.Duration  `yaml:"interval"`
-	Server       ServerConfig   `yaml:"server"`
-	Thresholds   Thresholds     `yaml:"thresholds"`
-	Discord      DiscordConfig  `yaml:"discord"`
-	RPCProviders []RPCProvider  `yaml:"rpc_providers"`
-	Dapps        []DappEndpoint `yaml:"dapps"`
-	StorePath    string         `yaml:"store_path"`
+	Interval     time.Duration       `yaml:"interval"`
+	Server       ServerConfig        `yaml:"server"`
+	Thresholds   Thresholds          `yaml:"thresholds"`
+	Incidents    IncidentPolicy      `yaml:"incidents"`
+	Discord      DiscordConfig       `yaml:"discord"`
+	RPCProviders []RPCProvider       `yaml:"rpc_providers"`
+	Dapps        []DappEndpoint      `yaml:"dapps"`
+	TCPTargets   []TCPTarget         `yaml:"tcp_targets"`
+	NodeMetrics  []NodeMetrics       `yaml:"node_metrics"`
+	Maintenance  []MaintenanceWindow `yaml:"maintenance"`
+	SLOs         []SLO               `yaml:"slos"`
+	StorePath    string              `yaml:"store_path"`
 }
 
 type ServerConfig struct {
@@ -38,13 +47,152 @@ type ServerConfig struct {
 	MetricsPath string `yaml:"metrics_path"`
 	EnablePprof bool   `yaml:"enable_pprof"`
 	PprofBind   string `yaml:"pprof_bind"`
+	// AdminToken enables the /v1/admin/ endpoints; requests must send it as a
+	// bearer token. Empty leaves the admin API disabled.
+	AdminToken string `yaml:"admin_token"`
 }
 
 type Thresholds struct {
-	LatencyWarnMS                  int `yaml:"latency_warn_ms"`
-	LatencyCritMS                  int `yaml:"latency_crit_ms"`
+	// LatencyWarnMS is compared with the p95 and LatencyCritMS with the p50 of
+	// successful checks among the last LatencyWindow checks.
+	LatencyWarnMS int `yaml:"latency_warn_ms"`
+	LatencyCritMS int `yaml:"latency_crit_ms"`
+	LatencyWindow int `yaml:"latency_window"`
+	// The clear thresholds are what latency must fall below before an open
+	// latency incident de-escalates or closes. Default 80% of the open ones.
+	LatencyWarnClearMS             int `yaml:"latency_warn_clear_ms"`
+	LatencyCritClearMS             int `yaml:"latency_crit_clear_ms"`
 	ConsecutiveFailuresForIncident int `yaml:"consecutive_failures_for_incident"`
 	RecoveriesForClose             int `yaml:"recoveries_for_close"`
+	// DegradedFailureRatePct opens a WARN "degraded" incident when at least
+	// this share of the last DegradedWindow checks failed, even without a
+	// run of consecutive failures. 0 disables the rule.
+	DegradedFailureRatePct int `yaml:"degraded_failure_rate_pct"`
+	DegradedWindow         int `yaml:"degraded_window"`
+	// CircuitOpenAfterFailures switches an entity to reduced-rate probing after
+	// this many consecutive failed checks. 0 disables the circuit breaker.
+	CircuitOpenAfterFailures int `yaml:"circuit_open_after_failures"`
+	CircuitProbeIntervalSecs int `yaml:"circuit_probe_interval_secs"`
+}
+
+// IncidentPolicy decides what automatic recovery does to an incident.
+type IncidentPolicy struct {
+	// OnRecovery is "resolve" (default) or "monitoring". Monitoring leaves the
+	// incident open until a human resolves it or, when ResolveAfterSecs > 0,
+	// until checks have stayed healthy that long.
+	OnRecovery       string `yaml:"on_recovery"`
+	ResolveAfterSecs int    `yaml:"monitoring_resolve_after_secs"`
+	// DeescalateAfterSecs is how long an open incident keeps its severity
+	// before it may be lowered. Escalation is immediate. Default 300; -1
+	// lowers severity as soon as checks allow.
+	DeescalateAfterSecs int `yaml:"deescalate_after_secs"`
+	// FlapThreshold marks an entity as flapping once this many incidents have
+	// opened for it within FlapWindowSecs (default 3600). A flapping incident
+	// stays open, without further alerts, until checks have passed for
+	// FlapStableSecs (default 900). 0 disables flap detection.
+	FlapThreshold  int `yaml:"flap_threshold"`
+	FlapWindowSecs int `yaml:"flap_window_secs"`
+	FlapStableSecs int `yaml:"flap_stable_secs"`
+	// CorrelateMinEntities groups RPC and dApp incidents under one parent
+	// incident once this many distinct entities have opened incidents within
+	// CorrelateWindowSecs (default 300). 0 disables correlation.
+	CorrelateMinEntities int `yaml:"correlate_min_entities"`
+	CorrelateWindowSecs  int `yaml:"correlate_window_secs"`
+	// ReportLinkWindowSecs is how long after resolving an incident user
+	// reports may still be linked to it. Default 1800.
+	ReportLinkWindowSecs int `yaml:"report_link_window_secs"`
+	// A user-reported incident opens when, within ReportSpikeWindowSecs
+	// (default 900), unlinked reports for one issue type, URL host or wallet
+	// reach ReportSpikeCount, or reach ReportSpikeFactor times their rate
+	// over the preceding ReportBaselineSecs (default 7 days) and at least
+	// ReportSpikeMinCount (default 3). 0 disables either rule.
+	ReportSpikeCount      int     `yaml:"report_spike_count"`
+	ReportSpikeFactor     float64 `yaml:"report_spike_factor"`
+	ReportSpikeMinCount   int     `yaml:"report_spike_min_count"`
+	ReportSpikeWindowSecs int     `yaml:"report_spike_window_secs"`
+	ReportBaselineSecs    int     `yaml:"report_baseline_secs"`
+}
+
+const (
+	RecoveryResolve    = "resolve"
+	RecoveryMonitoring = "monitoring"
+)
+
+// MaintenanceWindow silences incidents for matching entities between
+// StartsAt and EndsAt. Exactly one of Entity ("<type>/<name>"), Tag
+// ("key=value") or All selects what it covers.
+type MaintenanceWindow struct {
+	Entity   string    `yaml:"entity"`
+	Tag      string    `yaml:"tag"`
+	All      bool      `yaml:"all"`
+	StartsAt time.Time `yaml:"starts_at"`
+	EndsAt   time.Time `yaml:"ends_at"`
+	Reason   string    `yaml:"reason"`
+	// Mode is "suppress" (default), which opens no incidents, or
+	// "maintenance", which opens them flagged as maintenance. Alerts are
+	// muted either way.
+	Mode string `yaml:"mode"`
+}
+
+const (
+	MaintenanceSuppress = "suppress"
+	MaintenanceFlag     = "maintenance"
+)
+
+// Validate checks the scope and times and defaults Mode.
+func (m *MaintenanceWindow) Validate() error {
+	scopes := 0
+	if m.Entity != "" {
+		scopes++
+		typ, name, ok := strings.Cut(m.Entity, "/")
+		if !ok || name == "" {
+			return fmt.Errorf("entity must be <type>/<name>, got %q", m.Entity)
+		}
+		switch typ {
+		case "rpc", "dapp", "tcp", "node":
+		default:
+			return fmt.Errorf("entity type must be rpc, dapp, tcp or node, got %q", typ)
+		}
+	}
+	if m.Tag != "" {
+		scopes++
+		if k, _, ok := strings.Cut(m.Tag, "="); !ok || k == "" {
+			return fmt.Errorf("tag must be key=value, got %q", m.Tag)
+		}
+	}
+	if m.All {
+		scopes++
+	}
+	if scopes != 1 {
+		return fmt.Errorf("set exactly one of entity, tag or all")
+	}
+	if m.StartsAt.IsZero() || m.EndsAt.IsZero() {
+		return fmt.Errorf("starts_at and ends_at are required")
+	}
+	if !m.EndsAt.After(m.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	switch m.Mode {
+	case "":
+		m.Mode = MaintenanceSuppress
+	case MaintenanceSuppress, MaintenanceFlag:
+	default:
+		return fmt.Errorf("mode must be %q or %q, got %q", MaintenanceSuppress, MaintenanceFlag, m.Mode)
+	}
+	return nil
+}
+
+// SLO is a service level objective for one entity ("<type>/<name>"):
+// Objective percent of its checks over the last WindowDays must be good. A
+// check is good when it succeeded and, with LatencyMS set, took at most
+// LatencyMS, so "p95 latency under 800 ms" is objective 95 with latency_ms
+// 800.
+type SLO struct {
+	Name       string  `yaml:"name"`
+	Entity     string  `yaml:"entity"`
+	Objective  float64 `yaml:"objective"`
+	WindowDays int     `yaml:"window_days"`
+	LatencyMS  int     `yaml:"latency_ms"`
 }
 
 type DiscordConfig struct {
@@ -58,17 +206,116 @@ type DiscordConfig struct {
 }
 
 type RPCProvider struct {
-	Name    string            `yaml:"name"`
-	URL     string            `yaml:"url"`
-	Timeout durationMs        `yaml:"timeout_ms"`
-	Tags    map[string]string `yaml:"tags"`
+	Name                string            `yaml:"name"`
+	URL                 string            `yaml:"url"`
+	Timeout             durationMs        `yaml:"timeout_ms"`
+	HealthyDurationSecs int               `yaml:"healthy_duration_secs"`
+	DailyRequestBudget  int               `yaml:"daily_request_budget"`
+	ConnectionMode      string            `yaml:"connection_mode"`
+	IPFamilies          []string          `yaml:"ip_families"`
+	Tags                map[string]string `yaml:"tags"`
+	DependsOn           []string          `yaml:"depends_on"`
+	TransportConfig     `yaml:",inline"`
 }
 
 type DappEndpoint struct {
+	Name            string            `yaml:"name"`
+	URL             string            `yaml:"url"`
+	Timeout         durationMs        `yaml:"timeout_ms"`
+	ConnectionMode  string            `yaml:"connection_mode"`
+	IPFamilies      []string          `yaml:"ip_families"`
+	AssetChecks     *AssetChecks      `yaml:"asset_checks"`
+	Journey         []JourneyStep     `yaml:"journey"`
+	Tags            map[string]string `yaml:"tags"`
+	DependsOn       []string          `yaml:"depends_on"`
+	TransportConfig `yaml:",inline"`
+}
+
+// JourneyStep is one request of a multi-step synthetic check. URL, header
+// values and body may reference values extracted by earlier steps as {{name}}.
+type JourneyStep struct {
 	Name    string            `yaml:"name"`
+	Method  string            `yaml:"method"`
 	URL     string            `yaml:"url"`
-	Timeout durationMs        `yaml:"timeout_ms"`
-	Tags    map[string]string `yaml:"tags"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	Extract map[string]string `yaml:"extract"`
+	Assert  JourneyAssert     `yaml:"assert"`
+}
+
+type JourneyAssert struct {
+	Status       int               `yaml:"status"`
+	BodyContains string            `yaml:"body_contains"`
+	JSON         map[string]string `yaml:"json"`
+	MaxLatencyMs int64             `yaml:"max_latency_ms"`
+}
+
+type AssetChecks struct {
+	AllowedHosts []string          `yaml:"allowed_hosts"`
+	Pinned       map[string]string `yaml:"pinned"`
+	MaxAssets    int               `yaml:"max_assets"`
+}
+
+type TransportConfig struct {
+	ProxyURL           string `yaml:"proxy_url"`
+	CAFile             string `yaml:"ca_file"`
+	ClientCertFile     string `yaml:"client_cert_file"`
+	ClientKeyFile      string `yaml:"client_key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+type TCPTarget struct {
+	Name         string            `yaml:"name"`
+	Address      string            `yaml:"address"`
+	Timeout      durationMs        `yaml:"timeout_ms"`
+	TLS          bool              `yaml:"tls"`
+	ServerName   string            `yaml:"server_name"`
+	ReadBanner   bool              `yaml:"read_banner"`
+	ExpectBanner string            `yaml:"expect_banner"`
+	IPFamilies   []string          `yaml:"ip_families"`
+	Tags         map[string]string `yaml:"tags"`
+	DependsOn    []string          `yaml:"depends_on"`
+}
+
+const (
+	ConnectionModeWarm = "warm"
+	ConnectionModeCold = "cold"
+)
+
+const (
+	IPFamilyV4 = "ipv4"
+	IPFamilyV6 = "ipv6"
+)
+
+// FamilyEntityName is the entity name under which checks forced to one IP
+// family are recorded, e.g. "aptoslabs@ipv6". An empty family returns name.
+func FamilyEntityName(name, family string) string {
+	if family == "" {
+		return name
+	}
+	return name + "@" + family
+}
+
+type NodeMetrics struct {
+	Name      string            `yaml:"name"`
+	URL       string            `yaml:"url"`
+	Timeout   durationMs        `yaml:"timeout_ms"`
+	Rules     []MetricRule      `yaml:"rules"`
+	Tags      map[string]string `yaml:"tags"`
+	DependsOn []string          `yaml:"depends_on"`
+}
+
+type MetricRule struct {
+	Name      string            `yaml:"name"`
+	Metric    string            `yaml:"metric"`
+	Labels    map[string]string `yaml:"labels"`
+	Aggregate string            `yaml:"aggregate"`
+	Op        string            `yaml:"op"`
+	Value     float64           `yaml:"value"`
+	Severity  string            `yaml:"severity"`
+	// StaleAfter fails the rule when the value stays the same for this many
+	// consecutive scrapes; op is then optional.
+	StaleAfter int `yaml:"stale_after"`
 }
 
 func (r *RPCProvider) TimeoutMS() int {
@@ -79,6 +326,10 @@ func (d *DappEndpoint) TimeoutMS() int {
 	return int(d.Timeout.Duration() / time.Millisecond)
 }
 
+func (t *TCPTarget) TimeoutMS() int {
+	return int(t.Timeout.Duration() / time.Millisecond)
+}
+
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -109,6 +360,9 @@ func applyEnvOverrides(c *Config) {
 			c.Server.Port = p
 		}
 	}
+	if v := os.Getenv("APTOS_GUARDIAN_SERVER_ADMIN_TOKEN"); v != "" {
+		c.Server.AdminToken = v
+	}
 	if v := os.Getenv("APTOS_GUARDIAN_DISCORD_ENABLED"); v != "" {
 		c.Discord.Enabled = v == "1" || v == "true" || v == "yes"
 	}
@@ -151,12 +405,98 @@ func Validate(c *Config) error {
 	if c.Thresholds.LatencyCritMS <= 0 {
 		c.Thresholds.LatencyCritMS = 1500
 	}
+	if c.Thresholds.LatencyWindow <= 0 {
+		c.Thresholds.LatencyWindow = 20
+	}
+	if c.Thresholds.LatencyWarnClearMS <= 0 {
+		c.Thresholds.LatencyWarnClearMS = c.Thresholds.LatencyWarnMS * 4 / 5
+	}
+	if c.Thresholds.LatencyCritClearMS <= 0 {
+		c.Thresholds.LatencyCritClearMS = c.Thresholds.LatencyCritMS * 4 / 5
+	}
+	if c.Thresholds.LatencyWarnClearMS > c.Thresholds.LatencyWarnMS {
+		return fmt.Errorf("thresholds.latency_warn_clear_ms must not exceed latency_warn_ms")
+	}
+	if c.Thresholds.LatencyCritClearMS > c.Thresholds.LatencyCritMS {
+		return fmt.Errorf("thresholds.latency_crit_clear_ms must not exceed latency_crit_ms")
+	}
 	if c.Thresholds.ConsecutiveFailuresForIncident <= 0 {
 		c.Thresholds.ConsecutiveFailuresForIncident = 3
 	}
 	if c.Thresholds.RecoveriesForClose <= 0 {
 		c.Thresholds.RecoveriesForClose = 2
 	}
+	if c.Thresholds.DegradedFailureRatePct < 0 || c.Thresholds.DegradedFailureRatePct > 100 {
+		return fmt.Errorf("thresholds.degraded_failure_rate_pct must be between 0 and 100")
+	}
+	if c.Thresholds.DegradedWindow <= 0 {
+		c.Thresholds.DegradedWindow = 20
+	}
+	if c.Thresholds.CircuitOpenAfterFailures < 0 {
+		return fmt.Errorf("thresholds.circuit_open_after_failures must be >= 0")
+	}
+	if c.Thresholds.CircuitProbeIntervalSecs <= 0 {
+		c.Thresholds.CircuitProbeIntervalSecs = 300
+	}
+	switch c.Incidents.OnRecovery {
+	case "":
+		c.Incidents.OnRecovery = RecoveryResolve
+	case RecoveryResolve, RecoveryMonitoring:
+	default:
+		return fmt.Errorf("incidents.on_recovery: want %q or %q, got %q", RecoveryResolve, RecoveryMonitoring, c.Incidents.OnRecovery)
+	}
+	if c.Incidents.ResolveAfterSecs < 0 {
+		return fmt.Errorf("incidents.monitoring_resolve_after_secs must be >= 0")
+	}
+	if c.Incidents.DeescalateAfterSecs < -1 {
+		return fmt.Errorf("incidents.deescalate_after_secs must be >= 0, or -1 for no hold")
+	}
+	if c.Incidents.DeescalateAfterSecs == 0 {
+		c.Incidents.DeescalateAfterSecs = 300
+	}
+	if c.Incidents.FlapThreshold < 0 {
+		return fmt.Errorf("incidents.flap_threshold must be >= 0")
+	}
+	if c.Incidents.FlapWindowSecs <= 0 {
+		c.Incidents.FlapWindowSecs = 3600
+	}
+	if c.Incidents.FlapStableSecs <= 0 {
+		c.Incidents.FlapStableSecs = 900
+	}
+	if c.Incidents.CorrelateMinEntities < 0 {
+		return fmt.Errorf("incidents.correlate_min_entities must be >= 0")
+	}
+	if c.Incidents.CorrelateWindowSecs <= 0 {
+		c.Incidents.CorrelateWindowSecs = 300
+	}
+	if c.Incidents.ReportLinkWindowSecs <= 0 {
+		c.Incidents.ReportLinkWindowSecs = 1800
+	}
+	if c.Incidents.ReportSpikeCount < 0 {
+		return fmt.Errorf("incidents.report_spike_count must be >= 0")
+	}
+	if c.Incidents.ReportSpikeFactor < 0 {
+		return fmt.Errorf("incidents.report_spike_factor must be >= 0")
+	}
+	if c.Incidents.ReportSpikeMinCount <= 0 {
+		c.Incidents.ReportSpikeMinCount = 3
+	}
+	if c.Incidents.ReportSpikeWindowSecs <= 0 {
+		c.Incidents.ReportSpikeWindowSecs = 900
+	}
+	if c.Incidents.ReportBaselineSecs <= 0 {
+		c.Incidents.ReportBaselineSecs = 7 * 24 * 3600
+	}
+	known := c.entityKeys()
+	for i := range c.Maintenance {
+		m := &c.Maintenance[i]
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("maintenance[%d]: %w", i, err)
+		}
+		if m.Entity != "" && !known[m.Entity] {
+			return fmt.Errorf("maintenance[%d]: entity %q is not a configured <type>/<name>", i, m.Entity)
+		}
+	}
 	if c.Discord.DMRefuseMsg == "" {
 		c.Discord.DMRefuseMsg = "Please post in the support channel so the team can help. Mods never DM first."
 	}
@@ -165,8 +505,8 @@ func Validate(c *Config) error {
 	}
 	for i := range c.RPCProviders {
 		r := &c.RPCProviders[i]
-		if r.Name == "" {
-			return fmt.Errorf("rpc_providers[%d]: name required", i)
+		if err := validateEntityName(r.Name); err != nil {
+			return fmt.Errorf("rpc_providers[%d]: %w", i, err)
 		}
 		if r.URL == "" {
 			return fmt.Errorf("rpc_providers[%d]: url required", i)
@@ -174,14 +514,34 @@ func Validate(c *Config) error {
 		if r.Timeout.Duration() <= 0 {
 			r.Timeout = durationMs(4000) * durationMs(time.Millisecond)
 		}
+		if r.HealthyDurationSecs < 0 {
+			return fmt.Errorf("rpc_providers[%d]: healthy_duration_secs must be >= 0", i)
+		}
+		if r.DailyRequestBudget < 0 {
+			return fmt.Errorf("rpc_providers[%d]: daily_request_budget must be >= 0", i)
+		}
+		mode, err := validateConnectionMode(r.ConnectionMode)
+		if err != nil {
+			return fmt.Errorf("rpc_providers[%d]: %w", i, err)
+		}
+		r.ConnectionMode = mode
+		if err := validateTransport(&r.TransportConfig); err != nil {
+			return fmt.Errorf("rpc_providers[%d]: %w", i, err)
+		}
+		if err := validateIPFamilies(r.IPFamilies); err != nil {
+			return fmt.Errorf("rpc_providers[%d]: %w", i, err)
+		}
+		if len(r.IPFamilies) > 0 && r.ProxyURL != "" {
+			return fmt.Errorf("rpc_providers[%d]: ip_families cannot be combined with proxy_url", i)
+		}
 		if r.Tags == nil {
 			r.Tags = make(map[string]string)
 		}
 	}
 	for i := range c.Dapps {
 		d := &c.Dapps[i]
-		if d.Name == "" {
-			return fmt.Errorf("dapps[%d]: name required", i)
+		if err := validateEntityName(d.Name); err != nil {
+			return fmt.Errorf("dapps[%d]: %w", i, err)
 		}
 		if d.URL == "" {
 			return fmt.Errorf("dapps[%d]: url required", i)
@@ -189,10 +549,135 @@ func Validate(c *Config) error {
 		if d.Timeout.Duration() <= 0 {
 			d.Timeout = durationMs(4000) * durationMs(time.Millisecond)
 		}
+		mode, err := validateConnectionMode(d.ConnectionMode)
+		if err != nil {
+			return fmt.Errorf("dapps[%d]: %w", i, err)
+		}
+		d.ConnectionMode = mode
+		if err := validateTransport(&d.TransportConfig); err != nil {
+			return fmt.Errorf("dapps[%d]: %w", i, err)
+		}
+		if err := validateIPFamilies(d.IPFamilies); err != nil {
+			return fmt.Errorf("dapps[%d]: %w", i, err)
+		}
+		if len(d.IPFamilies) > 0 && d.ProxyURL != "" {
+			return fmt.Errorf("dapps[%d]: ip_families cannot be combined with proxy_url", i)
+		}
+		if a := d.AssetChecks; a != nil {
+			if a.MaxAssets <= 0 {
+				a.MaxAssets = 25
+			}
+			for assetURL, sri := range a.Pinned {
+				algo, _, ok := strings.Cut(sri, "-")
+				if !ok || (algo != "sha256" && algo != "sha384" && algo != "sha512") {
+					return fmt.Errorf("dapps[%d].asset_checks.pinned[%s]: want sha256-, sha384- or sha512-<base64>", i, assetURL)
+				}
+			}
+		}
+		for j := range d.Journey {
+			st := &d.Journey[j]
+			if st.URL == "" {
+				return fmt.Errorf("dapps[%d].journey[%d]: url required", i, j)
+			}
+			if st.Name == "" {
+				st.Name = fmt.Sprintf("step%d", j+1)
+			}
+			st.Method = strings.ToUpper(st.Method)
+			if st.Method == "" {
+				st.Method = http.MethodGet
+			}
+			for name, src := range st.Extract {
+				if !strings.HasPrefix(src, "$") && !strings.HasPrefix(src, "header:") {
+					return fmt.Errorf("dapps[%d].journey[%d].extract[%s]: want $.json.path or header:Name", i, j, name)
+				}
+			}
+			for path := range st.Assert.JSON {
+				if !strings.HasPrefix(path, "$") {
+					return fmt.Errorf("dapps[%d].journey[%d].assert.json[%s]: want $.json.path", i, j, path)
+				}
+			}
+		}
 		if d.Tags == nil {
 			d.Tags = make(map[string]string)
 		}
 	}
+	for i := range c.TCPTargets {
+		t := &c.TCPTargets[i]
+		if err := validateEntityName(t.Name); err != nil {
+			return fmt.Errorf("tcp_targets[%d]: %w", i, err)
+		}
+		if t.Address == "" {
+			return fmt.Errorf("tcp_targets[%d]: address required", i)
+		}
+		if _, _, err := net.SplitHostPort(t.Address); err != nil {
+			return fmt.Errorf("tcp_targets[%d]: address must be host:port: %w", i, err)
+		}
+		if err := validateIPFamilies(t.IPFamilies); err != nil {
+			return fmt.Errorf("tcp_targets[%d]: %w", i, err)
+		}
+		if t.Timeout.Duration() <= 0 {
+			t.Timeout = durationMs(4000) * durationMs(time.Millisecond)
+		}
+		if t.Tags == nil {
+			t.Tags = make(map[string]string)
+		}
+	}
+	for i := range c.NodeMetrics {
+		n := &c.NodeMetrics[i]
+		if err := validateEntityName(n.Name); err != nil {
+			return fmt.Errorf("node_metrics[%d]: %w", i, err)
+		}
+		if n.URL == "" {
+			return fmt.Errorf("node_metrics[%d]: url required", i)
+		}
+		if n.Timeout.Duration() <= 0 {
+			n.Timeout = durationMs(4000) * durationMs(time.Millisecond)
+		}
+		if n.Tags == nil {
+			n.Tags = make(map[string]string)
+		}
+		for j := range n.Rules {
+			r := &n.Rules[j]
+			if r.Metric == "" {
+				return fmt.Errorf("node_metrics[%d].rules[%d]: metric required", i, j)
+			}
+			if r.Name == "" {
+				r.Name = r.Metric
+			}
+			if r.StaleAfter < 0 {
+				return fmt.Errorf("node_metrics[%d].rules[%d]: stale_after must not be negative", i, j)
+			}
+			switch r.Op {
+			case "<", "<=", ">", ">=", "==", "!=":
+			case "":
+				if r.StaleAfter == 0 {
+					return fmt.Errorf("node_metrics[%d].rules[%d]: op or stale_after required", i, j)
+				}
+			default:
+				return fmt.Errorf("node_metrics[%d].rules[%d]: op %q must be one of < <= > >= == !=", i, j, r.Op)
+			}
+			switch r.Aggregate {
+			case "":
+				r.Aggregate = "sum"
+			case "sum", "max", "min":
+			default:
+				return fmt.Errorf("node_metrics[%d].rules[%d]: aggregate %q must be sum, max or min", i, j, r.Aggregate)
+			}
+			switch r.Severity {
+			case "":
+				r.Severity = "WARN"
+			case "WARN", "CRIT":
+			default:
+				return fmt.Errorf("node_metrics[%d].rules[%d]: severity %q must be WARN or CRIT", i, j, r.Severity)
+			}
+		}
+	}
+	if err := validateDependencies(c); err != nil {
+		return err
+	}
+	if err := validateSLOs(c); err != nil {
+		return err
+	}
 	if c.Discord.Enabled {
 		if c.Discord.BotToken == "" {
 			return fmt.Errorf("discord.enabled is true but bot_token is empty")
@@ -206,3 +691,178 @@ func Validate(c *Config) error {
 	}
 	return nil
 }
+
+// Dependencies returns the depends_on entries of every configured entity,
+// keyed by "<type>/<name>". Entities without dependencies are left out.
+func (c *Config) Dependencies() map[string][]string {
+	deps := make(map[string][]string)
+	add := func(entityType, name string, on []string) {
+		if len(on) > 0 {
+			deps[entityType+"/"+name] = on
+		}
+	}
+	for _, r := range c.RPCProviders {
+		add("rpc", r.Name, r.DependsOn)
+	}
+	for _, d := range c.Dapps {
+		add("dapp", d.Name, d.DependsOn)
+	}
+	for _, t := range c.TCPTargets {
+		add("tcp", t.Name, t.DependsOn)
+	}
+	for _, n := range c.NodeMetrics {
+		add("node", n.Name, n.DependsOn)
+	}
+	return deps
+}
+
+// entityKeys returns the configured entities as "<type>/<name>".
+func (c *Config) entityKeys() map[string]bool {
+	known := make(map[string]bool)
+	for _, r := range c.RPCProviders {
+		known["rpc/"+r.Name] = true
+	}
+	for _, d := range c.Dapps {
+		known["dapp/"+d.Name] = true
+	}
+	for _, t := range c.TCPTargets {
+		known["tcp/"+t.Name] = true
+	}
+	for _, n := range c.NodeMetrics {
+		known["node/"+n.Name] = true
+	}
+	return known
+}
+
+// validateEntityName requires a name without "@", which separates the IP
+// family in series names such as "name@ipv6".
+func validateEntityName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name required")
+	}
+	if strings.Contains(name, "@") {
+		return fmt.Errorf("name %q must not contain @", name)
+	}
+	return nil
+}
+
+// validateSLOs checks each SLO, defaults its window and name, and requires
+// names to be unique.
+func validateSLOs(c *Config) error {
+	known := c.entityKeys()
+	names := make(map[string]bool)
+	for i := range c.SLOs {
+		s := &c.SLOs[i]
+		if !known[s.Entity] {
+			return fmt.Errorf("slos[%d]: entity %q is not a configured <type>/<name>", i, s.Entity)
+		}
+		if s.Objective <= 0 || s.Objective >= 100 {
+			return fmt.Errorf("slos[%d]: objective must be between 0 and 100 (exclusive), got %v", i, s.Objective)
+		}
+		if s.WindowDays < 0 || s.LatencyMS < 0 {
+			return fmt.Errorf("slos[%d]: window_days and latency_ms must be >= 0", i)
+		}
+		if s.WindowDays == 0 {
+			s.WindowDays = 30
+		}
+		if s.Name == "" {
+			s.Name = s.Entity + " availability"
+			if s.LatencyMS > 0 {
+				s.Name = s.Entity + " latency"
+			}
+		}
+		if names[s.Name] {
+			return fmt.Errorf("slos[%d]: name %q used twice", i, s.Name)
+		}
+		names[s.Name] = true
+	}
+	return nil
+}
+
+// validateDependencies requires every depends_on entry to name a configured
+// entity as "<type>/<name>" and rejects cycles.
+func validateDependencies(c *Config) error {
+	known := c.entityKeys()
+	deps := c.Dependencies()
+	for entity, on := range deps {
+		for _, up := range on {
+			if !known[up] {
+				return fmt.Errorf("%s: depends_on %q is not a configured <type>/<name>", entity, up)
+			}
+			if up == entity {
+				return fmt.Errorf("%s: cannot depend on itself", entity)
+			}
+		}
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(entity string) error
+	visit = func(entity string) error {
+		switch state[entity] {
+		case visiting:
+			return fmt.Errorf("%s: depends_on forms a cycle", entity)
+		case done:
+			return nil
+		}
+		state[entity] = visiting
+		for _, up := range deps[entity] {
+			if err := visit(up); err != nil {
+				return err
+			}
+		}
+		state[entity] = done
+		return nil
+	}
+	for entity := range deps {
+		if err := visit(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateConnectionMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return ConnectionModeWarm, nil
+	case ConnectionModeWarm, ConnectionModeCold:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("connection_mode %q must be warm or cold", mode)
+	}
+}
+
+func validateTransport(t *TransportConfig) error {
+	if t.ProxyURL != "" {
+		u, err := url.Parse(t.ProxyURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("proxy_url %q is not a valid URL", t.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("proxy_url scheme %q must be http, https or socks5", u.Scheme)
+		}
+	}
+	if (t.ClientCertFile == "") != (t.ClientKeyFile == "") {
+		return fmt.Errorf("client_cert_file and client_key_file must be set together")
+	}
+	return nil
+}
+
+func validateIPFamilies(families []string) error {
+	seen := make(map[string]bool, len(families))
+	for _, f := range families {
+		if f != IPFamilyV4 && f != IPFamilyV6 {
+			return fmt.Errorf("ip_families: %q must be ipv4 or ipv6", f)
+		}
+		if seen[f] {
+			return fmt.Errorf("ip_families: %q listed twice", f)
+		}
+		seen[f] = true
+	}
+	return nil
+}
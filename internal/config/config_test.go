@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad_Example(t *testing.T) {
@@ -54,6 +56,61 @@ func TestLoad_MissingFile(t *testing.T) {
 	}
 }
 
+func TestValidate_LatencyClearAboveOpen(t *testing.T) {
+	c := &Config{Thresholds: Thresholds{LatencyWarnMS: 600, LatencyWarnClearMS: 700}}
+	if err := Validate(c); err == nil {
+		t.Error("expected error for clear threshold above open threshold")
+	}
+}
+
+func TestValidate_DegradedRate(t *testing.T) {
+	c := &Config{Thresholds: Thresholds{DegradedFailureRatePct: 120}}
+	if err := Validate(c); err == nil {
+		t.Error("expected error for failure rate above 100")
+	}
+	c = &Config{Thresholds: Thresholds{DegradedFailureRatePct: 30}}
+	if err := Validate(c); err != nil || c.Thresholds.DegradedWindow != 20 {
+		t.Errorf("Validate: %v, window %d", err, c.Thresholds.DegradedWindow)
+	}
+}
+
+func TestValidate_ReportSpike(t *testing.T) {
+	c := &Config{Incidents: IncidentPolicy{ReportSpikeFactor: -1}}
+	if err := Validate(c); err == nil {
+		t.Error("expected error for a negative report_spike_factor")
+	}
+	c = &Config{Incidents: IncidentPolicy{ReportSpikeCount: 10}}
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	p := c.Incidents
+	if p.ReportSpikeMinCount != 3 || p.ReportSpikeWindowSecs != 900 || p.ReportBaselineSecs != 604800 {
+		t.Errorf("report spike defaults = %d, %d, %d", p.ReportSpikeMinCount, p.ReportSpikeWindowSecs, p.ReportBaselineSecs)
+	}
+}
+
+func TestValidate_DeescalateAfter(t *testing.T) {
+	for _, tc := range []struct {
+		secs, want int
+		wantErr    bool
+	}{
+		{0, 300, false},
+		{60, 60, false},
+		{-1, -1, false},
+		{-2, 0, true},
+	} {
+		c := &Config{Incidents: IncidentPolicy{DeescalateAfterSecs: tc.secs}}
+		err := Validate(c)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("deescalate_after_secs %d: err = %v", tc.secs, err)
+			continue
+		}
+		if err == nil && c.Incidents.DeescalateAfterSecs != tc.want {
+			t.Errorf("deescalate_after_secs %d: got %d, want %d", tc.secs, c.Incidents.DeescalateAfterSecs, tc.want)
+		}
+	}
+}
+
 func TestValidate_Defaults(t *testing.T) {
 	c := &Config{}
 	if err := Validate(c); err != nil {
@@ -71,6 +128,12 @@ func TestValidate_Defaults(t *testing.T) {
 	if c.Thresholds.LatencyWarnMS != 600 {
 		t.Errorf("default latency_warn = %d", c.Thresholds.LatencyWarnMS)
 	}
+	if c.Thresholds.LatencyWindow != 20 || c.Thresholds.LatencyWarnClearMS != 480 || c.Thresholds.LatencyCritClearMS != 1200 {
+		t.Errorf("default latency window/clear = %d, %d, %d", c.Thresholds.LatencyWindow, c.Thresholds.LatencyWarnClearMS, c.Thresholds.LatencyCritClearMS)
+	}
+	if c.Thresholds.CircuitOpenAfterFailures != 0 || c.Thresholds.CircuitProbeIntervalSecs != 300 {
+		t.Errorf("default circuit = %d failures, %ds", c.Thresholds.CircuitOpenAfterFailures, c.Thresholds.CircuitProbeIntervalSecs)
+	}
 	if c.Discord.DMRefuseMsg == "" {
 		t.Error("dm_refuse_msg should be set")
 	}
@@ -94,6 +157,209 @@ func TestValidate_EmptyRPCURL(t *testing.T) {
 	}
 }
 
+func TestValidate_EntityNameWithAt(t *testing.T) {
+	cases := []*Config{
+		{RPCProviders: []RPCProvider{{Name: "labs@ipv6", URL: "https://labs"}}},
+		{Dapps: []DappEndpoint{{Name: "explorer@v2", URL: "https://explorer"}}},
+		{TCPTargets: []TCPTarget{{Name: "p2p@a", Address: "127.0.0.1:6180"}}},
+		{NodeMetrics: []NodeMetrics{{Name: "node@b", URL: "http://node:9101/metrics"}}},
+	}
+	for i, c := range cases {
+		if err := Validate(c); err == nil || !strings.Contains(err.Error(), "must not contain @") {
+			t.Errorf("case %d: err = %v", i, err)
+		}
+	}
+}
+
+func TestValidate_TCPTargetAddress(t *testing.T) {
+	c := &Config{
+		TCPTargets: []TCPTarget{{Name: "fullnode-p2p", Address: "fullnode.example.com"}},
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for address without port")
+	}
+	c.TCPTargets[0].Address = "fullnode.example.com:6182"
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c.TCPTargets[0].TimeoutMS() != 4000 {
+		t.Errorf("default timeout_ms = %d", c.TCPTargets[0].TimeoutMS())
+	}
+}
+
+func TestValidate_NodeMetricsRules(t *testing.T) {
+	c := &Config{
+		NodeMetrics: []NodeMetrics{{
+			Name: "fullnode", URL: "http://localhost:9101/metrics",
+			Rules: []MetricRule{{Metric: "aptos_connections", Op: ">="}},
+		}},
+	}
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	r := c.NodeMetrics[0].Rules[0]
+	if r.Name != "aptos_connections" || r.Aggregate != "sum" || r.Severity != "WARN" {
+		t.Errorf("rule defaults = %+v", r)
+	}
+	c.NodeMetrics[0].Rules[0].Op = "~"
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for invalid op")
+	}
+	c.NodeMetrics[0].Rules[0].Op = ""
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for a rule without op or stale_after")
+	}
+	c.NodeMetrics[0].Rules[0].StaleAfter = 5
+	if err := Validate(c); err != nil {
+		t.Errorf("stale_after without op: %v", err)
+	}
+}
+
+func TestValidate_ConnectionMode(t *testing.T) {
+	c := &Config{
+		RPCProviders: []RPCProvider{{Name: "x", URL: "https://x.com"}},
+		Dapps:        []DappEndpoint{{Name: "d", URL: "https://d.com", ConnectionMode: "cold"}},
+	}
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c.RPCProviders[0].ConnectionMode != ConnectionModeWarm {
+		t.Errorf("default connection_mode = %q", c.RPCProviders[0].ConnectionMode)
+	}
+	if c.Dapps[0].ConnectionMode != ConnectionModeCold {
+		t.Errorf("dapp connection_mode = %q", c.Dapps[0].ConnectionMode)
+	}
+	c.Dapps[0].ConnectionMode = "lukewarm"
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for invalid connection_mode")
+	}
+}
+
+func TestValidate_Transport(t *testing.T) {
+	c := &Config{
+		RPCProviders: []RPCProvider{{Name: "x", URL: "https://x.com", TransportConfig: TransportConfig{ProxyURL: "ftp://proxy:21"}}},
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for unsupported proxy scheme")
+	}
+	c.RPCProviders[0].ProxyURL = "http://proxy.internal:3128"
+	c.RPCProviders[0].ClientCertFile = "client.pem"
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for cert without key")
+	}
+	c.RPCProviders[0].ClientKeyFile = "client-key.pem"
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestValidate_Journey(t *testing.T) {
+	c := &Config{
+		Dapps: []DappEndpoint{{Name: "d", URL: "https://d.com", Journey: []JourneyStep{
+			{URL: "https://d.com/quote", Extract: map[string]string{"id": "data.id"}},
+		}}},
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for extract source without $ or header:")
+	}
+	c.Dapps[0].Journey[0].Extract["id"] = "$.data.id"
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	st := c.Dapps[0].Journey[0]
+	if st.Name != "step1" || st.Method != "GET" {
+		t.Errorf("defaults: name=%q method=%q", st.Name, st.Method)
+	}
+	c.Dapps[0].Journey = append(c.Dapps[0].Journey, JourneyStep{Name: "build"})
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for step without url")
+	}
+}
+
+func TestValidate_IPFamilies(t *testing.T) {
+	c := &Config{
+		Dapps: []DappEndpoint{{Name: "d", URL: "https://d.com", IPFamilies: []string{"ipv4", "ipv6"}}},
+	}
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	c.Dapps[0].IPFamilies = []string{"ipv6", "ipv6"}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for duplicate family")
+	}
+	c.Dapps[0].IPFamilies = []string{"v6"}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for unknown family")
+	}
+	c.Dapps[0].IPFamilies = []string{"ipv6"}
+	c.Dapps[0].ProxyURL = "http://proxy.example:3128"
+	if err := Validate(c); err == nil {
+		t.Fatal("expected error for ip_families with proxy_url")
+	}
+	if got := FamilyEntityName("explorer", IPFamilyV6); got != "explorer@ipv6" {
+		t.Errorf("FamilyEntityName = %q", got)
+	}
+}
+
+func TestValidate_Maintenance(t *testing.T) {
+	start := time.Date(2026, 11, 2, 2, 0, 0, 0, time.UTC)
+	c := &Config{
+		RPCProviders: []RPCProvider{{Name: "alchemy", URL: "https://alchemy"}},
+		Maintenance:  []MaintenanceWindow{{Entity: "rpc/alchemy", StartsAt: start, EndsAt: start.Add(2 * time.Hour)}},
+	}
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c.Maintenance[0].Mode != MaintenanceSuppress {
+		t.Errorf("default mode = %q", c.Maintenance[0].Mode)
+	}
+	bad := []MaintenanceWindow{
+		{StartsAt: start, EndsAt: start.Add(time.Hour)},
+		{Entity: "rpc/a", Tag: "k=v", StartsAt: start, EndsAt: start.Add(time.Hour)},
+		{Entity: "validator/a", StartsAt: start, EndsAt: start.Add(time.Hour)},
+		{Entity: "rpc/unknown", StartsAt: start, EndsAt: start.Add(time.Hour)},
+		{Tag: "tier", StartsAt: start, EndsAt: start.Add(time.Hour)},
+		{All: true, StartsAt: start, EndsAt: start},
+		{All: true, StartsAt: start, EndsAt: start.Add(time.Hour), Mode: "mute"},
+	}
+	for i, m := range bad {
+		c := &Config{Maintenance: []MaintenanceWindow{m}}
+		if err := Validate(c); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestValidate_DependsOn(t *testing.T) {
+	c := &Config{
+		RPCProviders: []RPCProvider{{Name: "labs", URL: "https://labs"}},
+		Dapps:        []DappEndpoint{{Name: "explorer", URL: "https://explorer", DependsOn: []string{"rpc/labs"}}},
+	}
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if deps := c.Dependencies(); len(deps) != 1 || deps["dapp/explorer"][0] != "rpc/labs" {
+		t.Errorf("Dependencies = %v", deps)
+	}
+	bad := [][]string{{"rpc/missing"}, {"labs"}, {"dapp/explorer"}}
+	for i, on := range bad {
+		c := &Config{
+			RPCProviders: []RPCProvider{{Name: "labs", URL: "https://labs"}},
+			Dapps:        []DappEndpoint{{Name: "explorer", URL: "https://explorer", DependsOn: on}},
+		}
+		if err := Validate(c); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+	cycle := &Config{
+		RPCProviders: []RPCProvider{{Name: "labs", URL: "https://labs", DependsOn: []string{"dapp/explorer"}}},
+		Dapps:        []DappEndpoint{{Name: "explorer", URL: "https://explorer", DependsOn: []string{"rpc/labs"}}},
+	}
+	if err := Validate(cycle); err == nil {
+		t.Error("expected error for a dependency cycle")
+	}
+}
+
 func TestValidate_DiscordEnabledNoToken(t *testing.T) {
 	c := &Config{Discord: DiscordConfig{Enabled: true, ApplicationID: "1", GuildID: "2"}}
 	if err := Validate(c); err == nil {
@@ -119,3 +385,32 @@ func TestEnvOverrides(t *testing.T) {
 		t.Errorf("store_path after env = %q", c.StorePath)
 	}
 }
+
+func TestValidate_SLOs(t *testing.T) {
+	base := func(slos ...SLO) *Config {
+		return &Config{RPCProviders: []RPCProvider{{Name: "aptoslabs", URL: "https://x"}}, SLOs: slos}
+	}
+	for _, bad := range []SLO{
+		{Entity: "rpc/missing", Objective: 99},
+		{Entity: "rpc/aptoslabs", Objective: 100},
+		{Entity: "rpc/aptoslabs", Objective: 0},
+		{Entity: "rpc/aptoslabs", Objective: 99, WindowDays: -1},
+	} {
+		if err := Validate(base(bad)); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+	if err := Validate(base(SLO{Entity: "rpc/aptoslabs", Objective: 99}, SLO{Entity: "rpc/aptoslabs", Objective: 99.9})); err == nil {
+		t.Error("expected error for duplicate SLO names")
+	}
+	c := base(SLO{Entity: "rpc/aptoslabs", Objective: 99.5}, SLO{Entity: "rpc/aptoslabs", Objective: 95, LatencyMS: 800})
+	if err := Validate(c); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c.SLOs[0].Name != "rpc/aptoslabs availability" || c.SLOs[0].WindowDays != 30 {
+		t.Errorf("defaults = %q, %d days", c.SLOs[0].Name, c.SLOs[0].WindowDays)
+	}
+	if c.SLOs[1].Name != "rpc/aptoslabs latency" {
+		t.Errorf("latency name = %q", c.SLOs[1].Name)
+	}
+}
@@ -31,8 +31,8 @@ func (a *Alerter) PostIncidentOpen(ctx context.Context, inc *store.Incident) err
 	if a.mention != "" {
 		prefix = a.mention + " "
 	}
-	msg := prefix + fmt.Sprintf("**🚨 Incident opened**\n**%s** / %s\nSeverity: %s\n%s\nStarted: %s",
-		inc.EntityType, inc.EntityName, inc.Severity, inc.Summary, inc.StartedAt.Format("2006-01-02 15:04:05 UTC"))
+	msg := prefix + fmt.Sprintf("**🚨 Incident opened**\n**%s** / %s\nSeverity: %s\n%s\nStarted: %s%s",
+		inc.EntityType, inc.EntityName, inc.Severity, inc.Summary, inc.StartedAt.Format("2006-01-02 15:04:05 UTC"), usersAffected(inc, "\n"))
 	_, err := a.session.ChannelMessageSend(a.alertChannelID, msg)
 	if err != nil {
 		a.log.Warn("alert post open", "err", err, "incident_id", inc.ID)
@@ -50,8 +50,8 @@ func (a *Alerter) PostIncidentClosed(ctx context.Context, inc *store.Incident) e
 	if inc.EndedAt != nil {
 		ended = inc.EndedAt.Format("2006-01-02 15:04:05 UTC")
 	}
-	msg := fmt.Sprintf("**✅ Incident closed**\n**%s** / %s\n%s\nEnded: %s",
-		inc.EntityType, inc.EntityName, inc.Summary, ended)
+	msg := fmt.Sprintf("**✅ Incident closed**\n**%s** / %s\n%s\nEnded: %s%s",
+		inc.EntityType, inc.EntityName, inc.Summary, ended, usersAffected(inc, "\n"))
 	_, err := a.session.ChannelMessageSend(a.alertChannelID, msg)
 	if err != nil {
 		a.log.Warn("alert post closed", "err", err, "incident_id", inc.ID)
@@ -61,6 +61,45 @@ func (a *Alerter) PostIncidentClosed(ctx context.Context, inc *store.Incident) e
 	return nil
 }
 
+func (a *Alerter) PostIncidentUpdate(ctx context.Context, inc *store.Incident, message string) error {
+	if a.alertChannelID == "" {
+		return nil
+	}
+	msg := fmt.Sprintf("**📝 Incident #%d update** (%s)\n**%s** / %s\n%s%s",
+		inc.ID, incidentStateLabel(inc), inc.EntityType, inc.EntityName, message, usersAffected(inc, "\n"))
+	_, err := a.session.ChannelMessageSend(a.alertChannelID, msg)
+	if err != nil {
+		a.log.Warn("alert post update", "err", err, "incident_id", inc.ID)
+		return err
+	}
+	a.log.Info("alert posted", "type", "update", "entity", inc.EntityName, "state", inc.State)
+	return nil
+}
+
+// PostSeverityChange announces an escalation, with the configured mention, or
+// a de-escalation.
+func (a *Alerter) PostSeverityChange(ctx context.Context, inc *store.Incident, previous string) error {
+	if a.alertChannelID == "" {
+		return nil
+	}
+	title := "**⬇️ Incident de-escalated**"
+	if inc.Severity == store.SeverityCrit {
+		title = "**⬆️ Incident escalated**"
+		if a.mention != "" {
+			title = a.mention + " " + title
+		}
+	}
+	msg := fmt.Sprintf("%s #%d\n**%s** / %s\nSeverity: %s → %s\n%s%s",
+		title, inc.ID, inc.EntityType, inc.EntityName, previous, inc.Severity, inc.Summary, usersAffected(inc, "\n"))
+	_, err := a.session.ChannelMessageSend(a.alertChannelID, msg)
+	if err != nil {
+		a.log.Warn("alert post severity", "err", err, "incident_id", inc.ID)
+		return err
+	}
+	a.log.Info("alert posted", "type", "severity", "entity", inc.EntityName, "severity", inc.Severity)
+	return nil
+}
+
 func (a *Alerter) PostRPCTransition(ctx context.Context, name, url string, healthy bool, latencyMs int64, errCat string) error {
 	if a.alertChannelID == "" {
 		return nil
@@ -83,13 +122,16 @@ func (a *Alerter) PostRPCTransition(ctx context.Context, name, url string, healt
 	return nil
 }
 
-func (a *Alerter) PostDappTransition(ctx context.Context, name, url string, healthy bool) error {
+func (a *Alerter) PostDappTransition(ctx context.Context, name, url string, healthy bool, errCat string) error {
 	if a.alertChannelID == "" {
 		return nil
 	}
 	status := "✅ Reachable"
 	if !healthy {
 		status = "❌ Unreachable"
+		if errCat != "" {
+			status += " (" + errCat + ")"
+		}
 	}
 	msg := fmt.Sprintf("**dApp status change:** %s → %s\nURL: %s", name, status, url)
 	_, err := a.session.ChannelMessageSend(a.alertChannelID, msg)
@@ -9,11 +9,15 @@ import (
 )
 
 const (
-	cmdStatus = "status"
-	cmdRPC    = "rpc"
-	cmdDapp   = "dapp"
-	cmdFix    = "fix"
-	cmdReport = "report"
+	cmdStatus      = "status"
+	cmdRPC         = "rpc"
+	cmdDapp        = "dapp"
+	cmdFix         = "fix"
+	cmdReport      = "report"
+	cmdIncident    = "incident"
+	cmdAck         = "ack"
+	cmdMaintenance = "maintenance"
+	cmdSLO         = "slo"
 )
 
 type CommandContextBuilder func(ctx context.Context) (*CommandContext, error)
@@ -58,6 +62,10 @@ func (b *Bot) Close() error {
 	return b.session.Close()
 }
 
+// responderPerms limits incident commands to members who can manage messages
+// unless server admins grant them more widely.
+var responderPerms int64 = discordgo.PermissionManageMessages
+
 func (b *Bot) RegisterCommands(ctx context.Context) error {
 	appID := b.cfg.ApplicationID
 	if appID == "" {
@@ -73,7 +81,46 @@ func (b *Bot) RegisterCommands(ctx context.Context) error {
 		{Name: cmdFix, Description: "Quick fix macros", Options: []*discordgo.ApplicationCommandOption{
 			{Type: discordgo.ApplicationCommandOptionString, Name: "topic", Description: "gas, staking, switch_rpc, scam", Required: true},
 		}},
-		{Name: cmdReport, Description: "Submit a guided report (use in support channel)"},
+		{Name: cmdReport, Description: "Submit a guided report (use in support channel)", Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "issue_type", Description: "What went wrong", Required: true, Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "RPC down or slow", Value: "rpc_down"},
+				{Name: "dApp error", Value: "dapp_error"},
+				{Name: "Wallet signing", Value: "wallet_sign"},
+				{Name: "Transaction failed", Value: "tx_failed"},
+				{Name: "Other", Value: "other"},
+			}},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "wallet", Description: "Wallet you use, e.g. Petra", Required: false},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "region", Description: "Your region", Required: false},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "url", Description: "Site or RPC URL affected", Required: false},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "What happened", Required: false},
+		}},
+		{Name: cmdSLO, Description: "SLO compliance and remaining error budget"},
+		{Name: cmdIncident, Description: "Move an incident to a new state", DefaultMemberPermissions: &responderPerms,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "id", Description: "Incident number", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "state", Description: "New state", Required: true, Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "investigating", Value: store.IncidentStateInvestigating},
+					{Name: "identified", Value: store.IncidentStateIdentified},
+					{Name: "monitoring", Value: store.IncidentStateMonitoring},
+					{Name: "resolved", Value: store.IncidentStateResolved},
+				}},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "message", Description: "Update for the incident timeline", Required: false},
+			}},
+		{Name: cmdAck, Description: "Acknowledge an incident", DefaultMemberPermissions: &responderPerms,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "id", Description: "Incident number", Required: true},
+			}},
+		{Name: cmdMaintenance, Description: "Schedule a maintenance window", DefaultMemberPermissions: &responderPerms,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "target", Description: "all, rpc/<name>, dapp/<name>, tcp/<name>, node/<name> or tag:key=value", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "duration", Description: "How long, e.g. 2h or 45m", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "starts_in", Description: "Delay before it starts, e.g. 30m (default now)", Required: false},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "reason", Description: "Shown on /status and the status page", Required: false},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "mode", Description: "What happens to incidents", Required: false, Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "suppress", Value: "suppress"},
+					{Name: "maintenance", Value: "maintenance"},
+				}},
+			}},
 	}
 	for _, c := range cmds {
 		_, err := b.session.ApplicationCommandCreate(appID, guildID, c)
@@ -111,6 +158,7 @@ func (b *Bot) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCr
 		b.respondErr(s, i, "Failed to build context.")
 		return
 	}
+	cmdCtx.User = interactionUser(i)
 	content, ephemeral := RunCommand(context.Background(), data.Name, opts, cmdCtx)
 	if ephemeral {
 		b.respondEphemeral(s, i, content)
@@ -119,6 +167,16 @@ func (b *Bot) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCr
 	}
 }
 
+func interactionUser(i *discordgo.InteractionCreate) string {
+	switch {
+	case i.Member != nil && i.Member.User != nil:
+		return i.Member.User.Username
+	case i.User != nil:
+		return i.User.Username
+	}
+	return ""
+}
+
 func (b *Bot) respond(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
 	if len(content) > 2000 {
 		content = content[:1997] + "..."
@@ -181,6 +239,9 @@ func BuildCommandContext(ctx context.Context, st *store.Store, engine interface
 			if c.LatencyMs.Valid {
 				ds.LatencyMs = c.LatencyMs.Int64
 			}
+			if c.ErrorCategory.Valid {
+				ds.LastError = c.ErrorCategory.String
+			}
 		}
 		cc.DappStatuses = append(cc.DappStatuses, ds)
 	}
@@ -2,11 +2,15 @@ package discordbot
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorusys/aptos-guardian/internal/incidents"
 	"github.com/gorusys/aptos-guardian/internal/macros"
+	"github.com/gorusys/aptos-guardian/internal/metrics"
 	"github.com/gorusys/aptos-guardian/internal/store"
 )
 
@@ -21,6 +25,7 @@ type DappStatus struct {
 	Name      string
 	Healthy   bool
 	LatencyMs int64
+	LastError string
 }
 
 type CommandContext struct {
@@ -32,6 +37,10 @@ type CommandContext struct {
 	RPCStatuses    []StatusProvider
 	DappStatuses   []DappStatus
 	OpenIncidents  []store.Incident
+	// Maintenance holds active and upcoming maintenance windows.
+	Maintenance []store.Maintenance
+	// User is the Discord user running the command, recorded on incident updates.
+	User string
 }
 
 func (c *CommandContext) BuildStatusResponse(ctx context.Context) string {
@@ -55,13 +64,22 @@ func (c *CommandContext) BuildStatusResponse(ctx context.Context) string {
 		status := "❌ Down"
 		if d.Healthy {
 			status = fmt.Sprintf("✅ %d ms", d.LatencyMs)
+		} else if d.LastError != "" {
+			status = "❌ " + d.LastError
 		}
 		b.WriteString(fmt.Sprintf("- %s: %s\n", d.Name, status))
 	}
+	if len(c.Maintenance) > 0 {
+		b.WriteString("\n**Maintenance:**\n")
+		now := time.Now()
+		for _, m := range c.Maintenance {
+			b.WriteString("- " + maintenanceLine(&m, now) + "\n")
+		}
+	}
 	if len(c.OpenIncidents) > 0 {
 		b.WriteString("\n**Open incidents:**\n")
 		for _, i := range c.OpenIncidents {
-			b.WriteString(fmt.Sprintf("- [%s] %s: %s\n", i.Severity, i.EntityName, i.Summary))
+			b.WriteString(fmt.Sprintf("- #%d [%s] %s (%s): %s%s\n", i.ID, i.Severity, i.EntityName, incidentStateLabel(&i), i.Summary, usersAffected(&i, " — ")))
 		}
 	}
 	return b.String()
@@ -95,6 +113,8 @@ func (c *CommandContext) BuildDappResponse(ctx context.Context, dappName string)
 			status := "❌ Down"
 			if d.Healthy {
 				status = fmt.Sprintf("✅ Up (%d ms)", d.LatencyMs)
+			} else if d.LastError != "" {
+				status = "❌ Down (" + d.LastError + ")"
 			}
 			msg := fmt.Sprintf("**%s:** %s\n", d.Name, status)
 			for _, i := range c.OpenIncidents {
@@ -109,6 +129,182 @@ func (c *CommandContext) BuildDappResponse(ctx context.Context, dappName string)
 	return fmt.Sprintf("Unknown dApp: `%s`. Known: %s.", dappName, strings.Join(c.DappNames, ", "))
 }
 
+func incidentStateLabel(inc *store.Incident) string {
+	label := inc.State
+	if inc.Flapping {
+		label += fmt.Sprintf(", flapping ×%d", inc.FlapCount)
+	}
+	if inc.Acknowledged {
+		label += ", acknowledged"
+	}
+	if inc.ParentID != 0 {
+		label += fmt.Sprintf(", part of #%d", inc.ParentID)
+	}
+	if inc.ImpactedBy != 0 {
+		label += fmt.Sprintf(", impacted by #%d", inc.ImpactedBy)
+	}
+	return label
+}
+
+// usersAffected describes the user reports linked to an incident after sep,
+// or returns "" when there are none.
+func usersAffected(inc *store.Incident, sep string) string {
+	switch inc.ReportCount {
+	case 0:
+		return ""
+	case 1:
+		return sep + "1 user affected"
+	}
+	return fmt.Sprintf("%s%d users affected", sep, inc.ReportCount)
+}
+
+// BuildIncidentTransition moves an incident to a new state for /incident.
+func (c *CommandContext) BuildIncidentTransition(ctx context.Context, idStr, state, message string) string {
+	if c.Engine == nil {
+		return "Incident updates are unavailable."
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(idStr), "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return "Usage: `/incident id:<number> state:<state> message:<text>`."
+	}
+	message = strings.TrimSpace(message)
+	if message != "" && c.User != "" {
+		message += " (" + c.User + ")"
+	}
+	inc, err := c.Engine.Transition(ctx, id, strings.ToLower(strings.TrimSpace(state)), message)
+	if err != nil {
+		return incidentCommandError(id, err)
+	}
+	return fmt.Sprintf("Incident #%d (%s) is now **%s**.", inc.ID, inc.EntityName, inc.State)
+}
+
+// BuildIncidentAck acknowledges an incident for /ack.
+func (c *CommandContext) BuildIncidentAck(ctx context.Context, idStr string) string {
+	if c.Engine == nil {
+		return "Incident updates are unavailable."
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(idStr), "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return "Usage: `/ack id:<number>`."
+	}
+	inc, err := c.Engine.Acknowledge(ctx, id, c.User)
+	if err != nil {
+		return incidentCommandError(id, err)
+	}
+	return fmt.Sprintf("Incident #%d (%s) acknowledged.", inc.ID, inc.EntityName)
+}
+
+func incidentCommandError(id int64, err error) string {
+	switch {
+	case errors.Is(err, incidents.ErrIncidentNotFound):
+		return fmt.Sprintf("Incident #%d not found.", id)
+	case errors.Is(err, incidents.ErrInvalidState):
+		return "State must be investigating, identified, monitoring or resolved."
+	case errors.Is(err, incidents.ErrIncidentResolved):
+		return fmt.Sprintf("Incident #%d is already resolved.", id)
+	default:
+		return "Failed to update incident."
+	}
+}
+
+func maintenanceLine(m *store.Maintenance, now time.Time) string {
+	scope := m.Entity
+	switch {
+	case m.All:
+		scope = "all entities"
+	case m.Tag != "":
+		scope = "tag " + m.Tag
+	}
+	const layout = "2006-01-02 15:04 UTC"
+	when := "until " + m.EndsAt.UTC().Format(layout)
+	if !m.Active(now) {
+		when = m.StartsAt.UTC().Format(layout) + " – " + m.EndsAt.UTC().Format(layout)
+	} else {
+		scope = "🔧 " + scope
+	}
+	line := scope + ", " + when
+	if m.Reason != "" {
+		line += ": " + m.Reason
+	}
+	return line
+}
+
+// BuildMaintenanceResponse schedules a window for /maintenance. target is
+// "all", "<type>/<name>" or "tag:key=value"; duration and startsIn are Go
+// durations such as "2h" or "30m".
+func (c *CommandContext) BuildMaintenanceResponse(ctx context.Context, target, duration, startsIn, reason, mode string) string {
+	if c.Engine == nil {
+		return "Maintenance scheduling is unavailable."
+	}
+	usage := "Usage: `/maintenance target:<all|rpc/name|tag:key=value> duration:<2h> [starts_in:<30m>] [reason:<text>]`."
+	d, err := time.ParseDuration(strings.TrimSpace(duration))
+	if err != nil || d <= 0 {
+		return usage
+	}
+	var delay time.Duration
+	if startsIn = strings.TrimSpace(startsIn); startsIn != "" {
+		if delay, err = time.ParseDuration(startsIn); err != nil || delay < 0 {
+			return usage
+		}
+	}
+	m := store.Maintenance{Reason: strings.TrimSpace(reason), Mode: mode, CreatedBy: c.User}
+	target = strings.TrimSpace(target)
+	switch {
+	case strings.EqualFold(target, "all"):
+		m.All = true
+	case strings.HasPrefix(target, "tag:"):
+		m.Tag = strings.TrimPrefix(target, "tag:")
+	default:
+		m.Entity = target
+	}
+	m.StartsAt = time.Now().UTC().Add(delay)
+	m.EndsAt = m.StartsAt.Add(d)
+	created, err := c.Engine.ScheduleMaintenance(ctx, m)
+	if err != nil {
+		return "Could not schedule maintenance: " + err.Error() + ".\n" + usage
+	}
+	return fmt.Sprintf("Maintenance #%d scheduled: %s.", created.ID, maintenanceLine(created, time.Now()))
+}
+
+// BuildSLOResponse lists each SLO with its compliance, remaining error
+// budget and one-hour burn rate for /slo.
+func (c *CommandContext) BuildSLOResponse(ctx context.Context) string {
+	if c.Engine == nil {
+		return "SLOs are unavailable."
+	}
+	statuses, err := c.Engine.SLOStatuses(ctx)
+	if err != nil {
+		return "Could not evaluate SLOs."
+	}
+	if len(statuses) == 0 {
+		return "No SLOs are configured."
+	}
+	var b strings.Builder
+	b.WriteString("**SLOs**\n")
+	for _, s := range statuses {
+		icon := "✅"
+		switch {
+		case s.Alert == store.SeverityCrit || s.BudgetRemaining <= 0:
+			icon = "🔥"
+		case s.Alert != "":
+			icon = "⚠️"
+		}
+		target := fmt.Sprintf("%g%% over %dd", s.Objective, s.WindowDays)
+		if s.LatencyMS > 0 {
+			target = fmt.Sprintf("%g%% under %d ms over %dd", s.Objective, s.LatencyMS, s.WindowDays)
+		}
+		burn := ""
+		for i, w := range incidents.BurnWindows {
+			if w == time.Hour {
+				burn = fmt.Sprintf(", burn %.1fx (1h)", s.BurnRates[i])
+			}
+		}
+		b.WriteString(fmt.Sprintf("%s %s: %.3f%% (target %s), %.0f%% of error budget left%s\n",
+			icon, s.Name, s.SLI, target, 100*s.BudgetRemaining, burn))
+	}
+	return b.String()
+}
+
 func (c *CommandContext) BuildFixResponse(topic string) string {
 	return macros.FixContent(topic)
 }
@@ -117,6 +313,37 @@ func (c *CommandContext) BuildReportAck() string {
 	return "Thanks for your report. The team will look into it. For urgent issues, post in the support channel."
 }
 
+// BuildReport stores a /report like POST /v1/report, linking it to the
+// incident it most likely concerns, and acknowledges it.
+func (c *CommandContext) BuildReport(ctx context.Context, options map[string]string) string {
+	issueType := strings.TrimSpace(options["issue_type"])
+	if issueType == "" {
+		return "Usage: `/report issue_type:<type>` with optional wallet, region, url and description."
+	}
+	if c.Engine == nil {
+		return "Reports are unavailable."
+	}
+	rep := &store.Report{
+		IssueType:   issueType,
+		Wallet:      options["wallet"],
+		Region:      options["region"],
+		URL:         options["url"],
+		Description: options["description"],
+		UserAgent:   "discord",
+	}
+	if c.User != "" {
+		rep.Reporter = store.ReporterKey("discord", c.User)
+	}
+	if _, err := c.Engine.SubmitReport(ctx, rep); err != nil {
+		return "Failed to save your report."
+	}
+	metrics.IncReportsTotal()
+	if rep.IncidentID.Valid {
+		return fmt.Sprintf("Thanks for your report. It is linked to incident #%d, which the team is already tracking.", rep.IncidentID.Int64)
+	}
+	return c.BuildReportAck()
+}
+
 func RunCommand(ctx context.Context, cmd string, options map[string]string, cc *CommandContext) (content string, ephemeral bool) {
 	if cc == nil {
 		return "Configuration error.", true
@@ -131,7 +358,15 @@ func RunCommand(ctx context.Context, cmd string, options map[string]string, cc *
 	case "fix":
 		return cc.BuildFixResponse(options["topic"]), false
 	case "report":
-		return cc.BuildReportAck(), true
+		return cc.BuildReport(ctx, options), true
+	case "incident":
+		return cc.BuildIncidentTransition(ctx, options["id"], options["state"], options["message"]), false
+	case "ack":
+		return cc.BuildIncidentAck(ctx, options["id"]), false
+	case "slo":
+		return cc.BuildSLOResponse(ctx), false
+	case "maintenance":
+		return cc.BuildMaintenanceResponse(ctx, options["target"], options["duration"], options["starts_in"], options["reason"], options["mode"]), false
 	default:
 		return "Unknown command.", true
 	}
@@ -2,10 +2,14 @@ package discordbot
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorusys/aptos-guardian/internal/config"
+	"github.com/gorusys/aptos-guardian/internal/incidents"
 	"github.com/gorusys/aptos-guardian/internal/store"
 )
 
@@ -145,6 +149,21 @@ func TestRunCommand_DappOption(t *testing.T) {
 	}
 }
 
+func TestBuildDappResponse_ErrorCategory(t *testing.T) {
+	cc := &CommandContext{
+		DappStatuses: []DappStatus{{Name: "explorer", Healthy: false, LastError: "dns"}},
+		DappNames:    []string{"explorer"},
+	}
+	out := cc.BuildDappResponse(context.Background(), "explorer")
+	if !strings.Contains(out, "dns") {
+		t.Errorf("expected error category in dapp response: %s", out)
+	}
+	status := cc.BuildStatusResponse(context.Background())
+	if !strings.Contains(status, "❌ dns") {
+		t.Errorf("expected error category in status response: %s", status)
+	}
+}
+
 func TestOpenIncidentInDappResponse(t *testing.T) {
 	cc := &CommandContext{
 		DappStatuses: []DappStatus{{Name: "explorer", Healthy: false, LatencyMs: 0}},
@@ -161,3 +180,115 @@ func TestOpenIncidentInDappResponse(t *testing.T) {
 		t.Error("expected incident summary")
 	}
 }
+
+func TestBuildIncidentCommandsUnavailable(t *testing.T) {
+	cc := &CommandContext{}
+	out, eph := RunCommand(context.Background(), "incident", map[string]string{"id": "1", "state": "identified"}, cc)
+	if eph || !strings.Contains(out, "unavailable") {
+		t.Errorf("incident without engine: %q", out)
+	}
+	if out := cc.BuildIncidentAck(context.Background(), "1"); !strings.Contains(out, "unavailable") {
+		t.Errorf("ack without engine: %q", out)
+	}
+}
+
+func TestStatusResponseShowsIncidentState(t *testing.T) {
+	cc := &CommandContext{
+		OpenIncidents: []store.Incident{
+			{ID: 7, EntityType: "rpc", EntityName: "p1", Severity: store.SeverityCrit, State: store.IncidentStateIdentified, Acknowledged: true, Summary: "Down", ReportCount: 37},
+		},
+	}
+	out := cc.BuildStatusResponse(context.Background())
+	if !strings.Contains(out, "#7") || !strings.Contains(out, "identified, acknowledged") {
+		t.Errorf("status should show id and state: %q", out)
+	}
+	if !strings.Contains(out, "37 users affected") {
+		t.Errorf("status should show report count: %q", out)
+	}
+}
+
+func TestMaintenanceCommandAndStatus(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	cfg := &config.Config{RPCProviders: []config.RPCProvider{{Name: "aptoslabs", URL: "https://aptoslabs"}}}
+	if err := config.Validate(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cc := &CommandContext{Engine: incidents.NewEngine(st, cfg, nil), User: "ops"}
+
+	if out := cc.BuildMaintenanceResponse(ctx, "rpc/aptoslabs", "soon", "", "", ""); !strings.Contains(out, "Usage") {
+		t.Errorf("bad duration: %q", out)
+	}
+	if out := cc.BuildMaintenanceResponse(ctx, "fullnode", "1h", "", "", ""); !strings.Contains(out, "Could not schedule") {
+		t.Errorf("bad target: %q", out)
+	}
+	out, _ := RunCommand(ctx, "maintenance", map[string]string{"target": "rpc/aptoslabs", "duration": "2h", "reason": "Provider upgrade"}, cc)
+	if !strings.Contains(out, "Maintenance #1 scheduled") {
+		t.Fatalf("schedule: %q", out)
+	}
+	_ = cc.BuildMaintenanceResponse(ctx, "tag:tier=premium", "1h", "3h", "", "")
+
+	cc.Maintenance, _ = cc.Engine.Maintenances(ctx)
+	status := cc.BuildStatusResponse(ctx)
+	if !strings.Contains(status, "🔧 rpc/aptoslabs, until") || !strings.Contains(status, "Provider upgrade") {
+		t.Errorf("active window missing from status: %q", status)
+	}
+	if !strings.Contains(status, "tag tier=premium, ") {
+		t.Errorf("upcoming window missing from status: %q", status)
+	}
+}
+
+func TestBuildSLOResponse(t *testing.T) {
+	ctx := context.Background()
+	if out, _ := RunCommand(ctx, "slo", nil, &CommandContext{}); !strings.Contains(out, "unavailable") {
+		t.Errorf("slo without engine: %q", out)
+	}
+	st, err := store.New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	cfg := &config.Config{
+		RPCProviders: []config.RPCProvider{{Name: "aptoslabs", URL: "https://x"}},
+		SLOs:         []config.SLO{{Entity: "rpc/aptoslabs", Objective: 99.5}},
+	}
+	if err := config.Validate(cfg); err != nil {
+		t.Fatal(err)
+	}
+	_ = st.InsertCheck(ctx, "rpc", "aptoslabs", true, nil, "")
+	cc := &CommandContext{Engine: incidents.NewEngine(st, cfg, nil)}
+	out := cc.BuildSLOResponse(ctx)
+	if !strings.Contains(out, "rpc/aptoslabs availability") || !strings.Contains(out, "99.5% over 30d") {
+		t.Errorf("slo response: %q", out)
+	}
+}
+
+func TestBuildReportStoresAndLinks(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	cfg := &config.Config{RPCProviders: []config.RPCProvider{{Name: "aptoslabs", URL: "https://api.aptoslabs.com"}}}
+	if err := config.Validate(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cc := &CommandContext{Engine: incidents.NewEngine(st, cfg, nil)}
+	if out := cc.BuildReport(ctx, nil); !strings.Contains(out, "Usage") {
+		t.Errorf("missing issue type: %q", out)
+	}
+	id, _ := st.OpenIncident(ctx, "rpc", "aptoslabs", "https://api.aptoslabs.com", store.SeverityCrit, "down")
+	out, ep := RunCommand(ctx, "report", map[string]string{"issue_type": "rpc_down", "url": "https://api.aptoslabs.com/v1", "wallet": "Petra"}, cc)
+	if !ep || !strings.Contains(out, fmt.Sprintf("incident #%d", id)) {
+		t.Errorf("report reply = %q, ephemeral %v", out, ep)
+	}
+	reports, _ := st.IncidentReports(ctx, id)
+	if len(reports) != 1 || reports[0].Wallet != "Petra" || reports[0].UserAgent != "discord" {
+		t.Errorf("stored reports = %+v", reports)
+	}
+}
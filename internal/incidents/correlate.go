@@ -0,0 +1,112 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// Parent incidents group RPC and dApp incidents that open close together.
+const (
+	ParentEntityType = "ecosystem"
+	ParentEntityName = "network"
+)
+
+// correlate links a newly opened RPC or dApp incident to the open parent
+// incident, or creates one when enough distinct entities have opened
+// incidents within Incidents.CorrelateWindowSecs. Linked incidents raise no
+// alerts of their own; the parent alerts once for the group. Incidents that
+// opened before the parent have already alerted.
+func (e *Engine) correlate(ctx context.Context, inc *store.Incident) error {
+	p := e.cfg.Incidents
+	if p.CorrelateMinEntities <= 0 || inc.Maintenance || inc.ImpactedBy != 0 || (inc.EntityType != "rpc" && inc.EntityType != "dapp") {
+		return nil
+	}
+	e.correlateMu.Lock()
+	defer e.correlateMu.Unlock()
+	hasParent, parentID, err := e.store.HasOpenIncident(ctx, ParentEntityType, ParentEntityName)
+	if err != nil {
+		return err
+	}
+	if hasParent {
+		return e.link(ctx, inc, parentID)
+	}
+	window := time.Duration(p.CorrelateWindowSecs) * time.Second
+	recent, err := e.store.OpenIncidentsSince(ctx, e.now().Add(-window))
+	if err != nil {
+		return err
+	}
+	var group []store.Incident
+	entities := map[string]bool{}
+	severity := store.SeverityWarn
+	for _, r := range recent {
+		if (r.EntityType != "rpc" && r.EntityType != "dapp") || r.ParentID != 0 || r.Maintenance || r.ImpactedBy != 0 {
+			continue
+		}
+		base, _, _ := strings.Cut(r.EntityName, "@")
+		entities[r.EntityType+"/"+base] = true
+		if r.Severity == store.SeverityCrit {
+			severity = store.SeverityCrit
+		}
+		group = append(group, r)
+	}
+	if len(entities) < p.CorrelateMinEntities {
+		return nil
+	}
+	summary := fmt.Sprintf("Multiple providers failing: possible network-wide issue (%d entities within %s).", len(entities), span(p.CorrelateWindowSecs))
+	parentID, err = e.store.OpenIncident(ctx, ParentEntityType, ParentEntityName, "", severity, summary)
+	if err != nil {
+		return err
+	}
+	_ = e.store.AddIncidentUpdate(ctx, parentID, summary)
+	for i := range group {
+		if err := e.link(ctx, &group[i], parentID); err != nil {
+			return err
+		}
+	}
+	e.log.Info("incident opened", "entity_type", ParentEntityType, "entity_name", ParentEntityName, "incident_id", parentID,
+		"severity", severity, "children", len(group))
+	e.alertOpen(ctx, parentID)
+	return nil
+}
+
+func (e *Engine) link(ctx context.Context, inc *store.Incident, parentID int64) error {
+	if err := e.store.SetIncidentParent(ctx, inc.ID, parentID); err != nil {
+		return err
+	}
+	inc.ParentID = parentID
+	_ = e.store.AddIncidentUpdate(ctx, parentID, fmt.Sprintf("Linked #%d %s/%s: %s", inc.ID, inc.EntityType, inc.EntityName, inc.Summary))
+	return nil
+}
+
+// resolveParent closes the parent of a resolved incident once every linked
+// incident is resolved.
+func (e *Engine) resolveParent(ctx context.Context, inc *store.Incident) error {
+	if inc.ParentID == 0 {
+		return nil
+	}
+	parent, err := e.store.GetIncident(ctx, inc.ParentID)
+	if err != nil || !parent.Open() {
+		return err
+	}
+	children, err := e.store.ChildIncidents(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		if c.Open() {
+			return nil
+		}
+	}
+	const summary = "All linked incidents resolved."
+	if err := e.store.CloseIncident(ctx, parent.ID, summary); err != nil {
+		return err
+	}
+	_ = e.store.AddIncidentUpdate(ctx, parent.ID, summary)
+	e.alertClosed(ctx, parent.ID)
+	e.log.Info("incident closed", "entity_type", parent.EntityType, "entity_name", parent.EntityName, "incident_id", parent.ID)
+	return nil
+}
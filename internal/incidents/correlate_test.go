@@ -0,0 +1,134 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_CorrelateUnderParent(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.CorrelateMinEntities = 3
+	eng.cfg.Incidents.CorrelateWindowSecs = 300
+	eng.cfg.Thresholds.DegradedFailureRatePct = 0
+	var opened, closed []string
+	eng.OnIncidentOpen = func(_ context.Context, inc *store.Incident) {
+		opened = append(opened, inc.EntityType+"/"+inc.EntityName)
+	}
+	eng.OnIncidentClosed = func(_ context.Context, inc *store.Incident) {
+		closed = append(closed, inc.EntityType+"/"+inc.EntityName)
+	}
+	check := func(name string, success bool) {
+		errCat := ""
+		if !success {
+			errCat = "timeout"
+		}
+		_ = st.InsertCheck(ctx, "dapp", name, success, int64Ptr(5), errCat)
+		if _, _, err := eng.ProcessDappResult(ctx, name, "https://"+name, success); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fail := func(name string) { check(name, false); check(name, false) }
+
+	fail("a")
+	fail("b")
+	if len(opened) != 2 {
+		t.Fatalf("below the minimum incidents alert alone: %v", opened)
+	}
+	fail("c")
+	hasParent, parentID, _ := st.HasOpenIncident(ctx, ParentEntityType, ParentEntityName)
+	if !hasParent {
+		t.Fatal("third entity should open a parent")
+	}
+	if len(opened) != 3 || opened[2] != "ecosystem/network" {
+		t.Fatalf("parent should alert instead of the third child: %v", opened)
+	}
+	fail("d")
+	children, _ := st.ChildIncidents(ctx, parentID)
+	if len(children) != 4 {
+		t.Fatalf("children = %d, want 4", len(children))
+	}
+	if len(opened) != 3 {
+		t.Errorf("later incidents join the parent silently: %v", opened)
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		check(name, true)
+		check(name, true)
+	}
+	if parent, _ := st.GetIncident(ctx, parentID); !parent.Open() {
+		t.Fatal("parent closed while a child is open")
+	}
+	if len(closed) != 0 {
+		t.Errorf("children should not alert on close: %v", closed)
+	}
+	check("d", true)
+	check("d", true)
+	parent, _ := st.GetIncident(ctx, parentID)
+	if parent.Open() || parent.Summary != "All linked incidents resolved." {
+		t.Errorf("parent should resolve with its last child: %+v", parent)
+	}
+	if len(closed) != 1 || closed[0] != "ecosystem/network" {
+		t.Errorf("closed alerts = %v", closed)
+	}
+}
+
+func TestEngine_CorrelateConcurrent(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.CorrelateMinEntities = 3
+	eng.cfg.Incidents.CorrelateWindowSecs = 300
+	eng.cfg.Thresholds.DegradedFailureRatePct = 0
+	const n = 8
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("d%d", i)
+		_ = st.InsertCheck(ctx, "dapp", name, false, int64Ptr(5), "timeout")
+		_ = st.InsertCheck(ctx, "dapp", name, false, int64Ptr(5), "timeout")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if _, _, err := eng.ProcessDappResult(ctx, name, "https://"+name, false); err != nil {
+				t.Error(err)
+			}
+		}(fmt.Sprintf("d%d", i))
+	}
+	wg.Wait()
+	open, _ := st.ListIncidents(ctx, store.IncidentStateOpen, 100)
+	parents := 0
+	var parentID int64
+	for _, inc := range open {
+		if inc.EntityType == ParentEntityType {
+			parents++
+			parentID = inc.ID
+		}
+	}
+	if parents != 1 {
+		t.Fatalf("parents = %d, want 1", parents)
+	}
+	if children, _ := st.ChildIncidents(ctx, parentID); len(children) != n {
+		t.Errorf("children = %d, want %d", len(children), n)
+	}
+}
+
+func TestEngine_CorrelateDisabled(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.CorrelateMinEntities = 0
+	for _, name := range []string{"a", "b", "c", "d"} {
+		id, _ := st.OpenIncident(ctx, "rpc", name, "", store.SeverityCrit, "down")
+		inc, _ := st.GetIncident(ctx, id)
+		if err := eng.correlate(ctx, inc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if has, _, _ := st.HasOpenIncident(ctx, ParentEntityType, ParentEntityName); has {
+		t.Error("correlation disabled should not open a parent")
+	}
+}
@@ -0,0 +1,44 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// checkWindow is how many recent checks the open and close rules need.
+func (e *Engine) checkWindow() int {
+	t := e.cfg.Thresholds
+	return max(t.ConsecutiveFailuresForIncident+t.RecoveriesForClose+2, t.DegradedWindow)
+}
+
+// failureRate returns the failure percentage over the degraded window and
+// whether it reaches Thresholds.DegradedFailureRatePct. It needs a full
+// window of checks and reports false while the rule is disabled.
+func (e *Engine) failureRate(checks []store.CheckRow) (pct int, degraded bool) {
+	t := e.cfg.Thresholds
+	if t.DegradedFailureRatePct <= 0 || t.DegradedWindow <= 0 || len(checks) < t.DegradedWindow {
+		return 0, false
+	}
+	failed := 0
+	for _, c := range checks[:t.DegradedWindow] {
+		if !c.Success {
+			failed++
+		}
+	}
+	pct = failed * 100 / t.DegradedWindow
+	return pct, failed*100 >= t.DegradedFailureRatePct*t.DegradedWindow
+}
+
+// openDegraded opens a WARN incident for an entity that fails often but not
+// consecutively enough for the CRIT rule.
+func (e *Engine) openDegraded(ctx context.Context, entityType, name, url string, checks []store.CheckRow) (bool, error) {
+	pct, degraded := e.failureRate(checks)
+	if !degraded {
+		return false, nil
+	}
+	summary := fmt.Sprintf("Degraded: %d%% of the last %d checks failed.", pct, e.cfg.Thresholds.DegradedWindow)
+	id, err := e.raise(ctx, entityType, name, url, store.SeverityWarn, summary, "failure_rate_pct", pct)
+	return id != 0, err
+}
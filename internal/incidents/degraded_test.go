@@ -0,0 +1,70 @@
+package incidents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_DegradedOnFailureRate(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Thresholds.ConsecutiveFailuresForIncident = 3
+	eng.cfg.Thresholds.DegradedFailureRatePct = 30
+	eng.cfg.Thresholds.DegradedWindow = 10
+	check := func(success bool) (opened, closed bool) {
+		var lat *int64
+		errCat := "http_5xx"
+		if success {
+			lat, errCat = int64Ptr(100), ""
+		}
+		_ = st.InsertCheck(ctx, "dapp", "explorer", success, lat, errCat)
+		opened, closed, err := eng.ProcessDappResult(ctx, "explorer", "https://explorer.example", success)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return opened, closed
+	}
+
+	// Failing every other check never reaches three in a row.
+	opened := false
+	for i := 0; i < 10 && !opened; i++ {
+		opened, _ = check(i%2 == 1)
+	}
+	if !opened {
+		t.Fatal("50% failures should open a degraded incident")
+	}
+	_, id, _ := st.HasOpenIncident(ctx, "dapp", "explorer")
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.Severity != store.SeverityWarn || inc.Summary != "Degraded: 50% of the last 10 checks failed." {
+		t.Errorf("incident = %s %q", inc.Severity, inc.Summary)
+	}
+
+	// Two successes in a row would close a CRIT incident, but the rate is still high.
+	check(true)
+	if _, closed := check(true); closed {
+		t.Error("degraded incident closed while failure rate still high")
+	}
+	closed := false
+	for i := 0; i < 10 && !closed; i++ {
+		_, closed = check(true)
+	}
+	if !closed {
+		t.Error("degraded incident should close once the failure rate drops")
+	}
+}
+
+func TestEngine_DegradedDisabled(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Thresholds.ConsecutiveFailuresForIncident = 3
+	eng.cfg.Thresholds.DegradedFailureRatePct = 0
+	for i := 0; i < 30; i++ {
+		success := i%2 == 1
+		_ = st.InsertCheck(ctx, "rpc", "p", success, int64Ptr(100), "")
+		if opened, _, _ := eng.ProcessRPCResult(ctx, "p", "https://p.example", success); opened {
+			t.Fatal("failure-rate rule is disabled")
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// upstreamIncident returns the open incident of the first dependency of an
+// entity that has one, or nil. Family series take the dependencies of their
+// base entity.
+func (e *Engine) upstreamIncident(ctx context.Context, entityType, name string) *store.Incident {
+	base, _, _ := strings.Cut(name, "@")
+	for _, up := range e.cfg.Dependencies()[entityType+"/"+base] {
+		upType, upName, _ := strings.Cut(up, "/")
+		has, id, err := e.store.HasOpenIncident(ctx, upType, upName)
+		if err != nil || !has {
+			continue
+		}
+		if inc, err := e.store.GetIncident(ctx, id); err == nil {
+			return inc
+		}
+	}
+	return nil
+}
+
+// endImpact runs while an impacted incident keeps failing. Once its upstream
+// incident has resolved it moves to another open upstream incident, if any,
+// or becomes an incident of its own and is announced.
+func (e *Engine) endImpact(ctx context.Context, inc *store.Incident) error {
+	if inc.ImpactedBy == 0 {
+		return nil
+	}
+	prev, err := e.store.GetIncident(ctx, inc.ImpactedBy)
+	if err != nil || prev.Open() {
+		return err
+	}
+	if up := e.upstreamIncident(ctx, inc.EntityType, inc.EntityName); up != nil {
+		inc.ImpactedBy = up.ID
+		if err := e.store.SetIncidentImpactedBy(ctx, inc.ID, up.ID); err != nil {
+			return err
+		}
+		return e.store.AddIncidentUpdate(ctx, inc.ID, fmt.Sprintf("Upstream incident #%d resolved; impacted by #%d %s/%s.", prev.ID, up.ID, up.EntityType, up.EntityName))
+	}
+	inc.ImpactedBy = 0
+	if err := e.store.SetIncidentImpactedBy(ctx, inc.ID, 0); err != nil {
+		return err
+	}
+	_ = e.store.AddIncidentUpdate(ctx, inc.ID, fmt.Sprintf("Still failing after upstream incident #%d resolved.", prev.ID))
+	e.alertOpen(ctx, inc.ID)
+	e.log.Info("incident no longer impacted", "entity_type", inc.EntityType, "entity_name", inc.EntityName,
+		"incident_id", inc.ID, "upstream_id", prev.ID)
+	return nil
+}
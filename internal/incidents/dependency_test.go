@@ -0,0 +1,75 @@
+package incidents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_ImpactedByUpstream(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Thresholds.DegradedFailureRatePct = 0
+	eng.cfg.Dapps[0].DependsOn = []string{"rpc/aptoslabs"}
+	dapp := eng.cfg.Dapps[0].Name
+	var opened []string
+	eng.OnIncidentOpen = func(_ context.Context, inc *store.Incident) {
+		opened = append(opened, inc.EntityType+"/"+inc.EntityName)
+	}
+	check := func(success bool) {
+		errCat := ""
+		if !success {
+			errCat = "timeout"
+		}
+		_ = st.InsertCheck(ctx, "dapp", dapp, success, int64Ptr(5), errCat)
+		if _, _, err := eng.ProcessDappResult(ctx, dapp, "https://x", success); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	upID, _ := st.OpenIncident(ctx, "rpc", "aptoslabs", "", store.SeverityCrit, "down")
+	check(false)
+	check(false)
+	check(false)
+	_, id, _ := st.HasOpenIncident(ctx, "dapp", dapp)
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.ImpactedBy != upID || inc.Severity != store.SeverityWarn {
+		t.Fatalf("downstream incident should be WARN and impacted: %+v", inc)
+	}
+	if len(opened) != 0 {
+		t.Errorf("impacted incident should not alert: %v", opened)
+	}
+
+	_ = st.CloseIncident(ctx, upID, "recovered")
+	check(false)
+	inc, _ = st.GetIncident(ctx, id)
+	if inc.ImpactedBy != 0 {
+		t.Errorf("impact should end with the upstream incident: %+v", inc)
+	}
+	if len(opened) != 1 || opened[0] != "dapp/"+dapp {
+		t.Errorf("still failing downstream should be announced: %v", opened)
+	}
+	check(false)
+	if inc, _ = st.GetIncident(ctx, id); inc.Severity != store.SeverityCrit {
+		t.Errorf("severity = %s, want CRIT once judged on its own", inc.Severity)
+	}
+}
+
+func TestEngine_UpstreamHealthyOpensNormally(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Dapps[0].DependsOn = []string{"rpc/aptoslabs"}
+	dapp := eng.cfg.Dapps[0].Name
+	for range 2 {
+		_ = st.InsertCheck(ctx, "dapp", dapp, false, int64Ptr(5), "timeout")
+		if _, _, err := eng.ProcessDappResult(ctx, dapp, "https://x", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, id, _ := st.HasOpenIncident(ctx, "dapp", dapp)
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.ImpactedBy != 0 || inc.Severity != store.SeverityCrit {
+		t.Errorf("incident = %+v", inc)
+	}
+}
@@ -2,7 +2,11 @@ package incidents
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorusys/aptos-guardian/internal/config"
 	"github.com/gorusys/aptos-guardian/internal/store"
@@ -14,24 +18,36 @@ type Engine struct {
 	log              *slog.Logger
 	OnIncidentOpen   func(ctx context.Context, inc *store.Incident)
 	OnIncidentClosed func(ctx context.Context, inc *store.Incident)
+	// OnIncidentUpdated fires for state changes short of resolution and for
+	// acknowledgements, with the message recorded in the timeline.
+	OnIncidentUpdated func(ctx context.Context, inc *store.Incident, message string)
+	// OnSeverityChanged fires when an open incident is escalated or
+	// de-escalated; previous is the severity it had before.
+	OnSeverityChanged func(ctx context.Context, inc *store.Incident, previous string)
+	now               func() time.Time
+	// correlateMu serializes correlate, which checkers for different
+	// entities call concurrently, so one parent opens per group.
+	correlateMu sync.Mutex
 }
 
 func NewEngine(st *store.Store, cfg *config.Config, log *slog.Logger) *Engine {
 	if log == nil {
 		log = slog.Default()
 	}
-	return &Engine{store: st, cfg: cfg, log: log}
+	return &Engine{store: st, cfg: cfg, log: log, now: time.Now}
 }
 
-func (e *Engine) ProcessRPCResult(ctx context.Context, name, url string, success bool, latencyMs int64) (opened, closed bool, err error) {
-	checks, err := e.store.RecentChecks(ctx, "rpc", name, e.cfg.Thresholds.ConsecutiveFailuresForIncident+e.cfg.Thresholds.RecoveriesForClose+2)
+// ProcessRPCResult opens, re-grades and closes RPC incidents. Latency is
+// judged over a window of recent checks rather than the latest sample, so
+// the check must be recorded before it is processed.
+func (e *Engine) ProcessRPCResult(ctx context.Context, name, url string, success bool) (opened, closed bool, err error) {
+	t := e.cfg.Thresholds
+	checks, err := e.store.RecentChecks(ctx, "rpc", name, max(t.LatencyWindow, e.checkWindow()))
 	if err != nil {
 		return false, false, err
 	}
-	openThreshold := e.cfg.Thresholds.ConsecutiveFailuresForIncident
-	closeThreshold := e.cfg.Thresholds.RecoveriesForClose
-	latWarn := e.cfg.Thresholds.LatencyWarnMS
-	latCrit := e.cfg.Thresholds.LatencyCritMS
+	openThreshold := t.ConsecutiveFailuresForIncident
+	lat := windowLatency(checks, t.LatencyWindow)
 
 	hasOpen, openID, err := e.store.HasOpenIncident(ctx, "rpc", name)
 	if err != nil {
@@ -39,108 +55,201 @@ func (e *Engine) ProcessRPCResult(ctx context.Context, name, url string, success
 	}
 
 	if hasOpen {
-		if success {
-			consecutiveSuccess := countConsecutiveSuccess(checks, true)
-			if consecutiveSuccess >= closeThreshold {
-				summary := "RPC recovered after consecutive successes."
-				if closeErr := e.store.CloseIncident(ctx, openID, summary); closeErr != nil {
-					return false, false, closeErr
-				}
-				_ = e.store.AddIncidentUpdate(ctx, openID, summary)
-				e.alertClosed(ctx, openID)
-				e.log.Info("incident closed", "entity_type", "rpc", "entity_name", name, "incident_id", openID)
-				return false, true, nil
-			}
+		inc, err := e.store.GetIncident(ctx, openID)
+		if err != nil {
+			return false, false, err
 		}
-		return false, false, nil
+		level := e.latencyLevel(lat, inc.Severity)
+		desired, reason := level, lat.describe(level)
+		if !success && countConsecutiveSuccess(checks, false) >= openThreshold {
+			desired, reason = store.SeverityCrit, "RPC unreachable or failing."
+		}
+		steady := countConsecutiveSuccess(checks, true) >= t.RecoveriesForClose
+		if err := e.reviewSeverity(ctx, openID, desired, reason, steady); err != nil {
+			return false, false, err
+		}
+		// A provider that answers but is still slow has not recovered.
+		closed, err := e.processOpenIncident(ctx, "rpc", name, openID, success, level == "", checks, "RPC recovered after consecutive successes.")
+		return false, closed, err
 	}
 
 	if !success {
 		consecutiveFail := countConsecutiveSuccess(checks, false)
 		if consecutiveFail >= openThreshold {
 			summary := "RPC unreachable or failing (consecutive failures)."
-			id, openErr := e.store.OpenIncident(ctx, "rpc", name, url, store.SeverityCrit, summary)
-			if openErr != nil {
-				return false, false, openErr
+			if errCat := lastErrorCategory(checks); errCat != "" {
+				summary = "RPC unreachable or failing (consecutive failures, last error: " + errCat + ")."
 			}
-			_ = e.store.AddIncidentUpdate(ctx, id, summary)
-			e.alertOpen(ctx, id)
-			e.log.Info("incident opened", "entity_type", "rpc", "entity_name", name, "incident_id", id, "severity", store.SeverityCrit)
-			return true, false, nil
+			id, err := e.raise(ctx, "rpc", name, url, store.SeverityCrit, summary)
+			return id != 0, false, err
 		}
+	}
+	if opened, err := e.openDegraded(ctx, "rpc", name, url, checks); opened || err != nil {
+		return opened, false, err
+	}
+	if !success {
 		return false, false, nil
 	}
 
-	if latencyMs >= int64(latCrit) {
-		summary := "RPC latency critical (above threshold)."
-		id, openErr := e.store.OpenIncident(ctx, "rpc", name, url, store.SeverityCrit, summary)
-		if openErr != nil {
-			return false, false, openErr
-		}
-		_ = e.store.AddIncidentUpdate(ctx, id, summary)
-		e.alertOpen(ctx, id)
-		e.log.Info("incident opened", "entity_type", "rpc", "entity_name", name, "incident_id", id, "severity", store.SeverityCrit)
-		return true, false, nil
+	// Wait for half a window of samples so one slow check cannot open an incident.
+	if lat.samples < (t.LatencyWindow+1)/2 {
+		return false, false, nil
 	}
-	if latencyMs >= int64(latWarn) {
-		summary := "RPC latency elevated (warning)."
-		id, openErr := e.store.OpenIncident(ctx, "rpc", name, url, store.SeverityWarn, summary)
-		if openErr != nil {
-			return false, false, openErr
-		}
-		_ = e.store.AddIncidentUpdate(ctx, id, summary)
-		e.alertOpen(ctx, id)
-		e.log.Info("incident opened", "entity_type", "rpc", "entity_name", name, "incident_id", id, "severity", store.SeverityWarn)
-		return true, false, nil
+	severity := e.latencyLevel(lat, "")
+	if severity == "" {
+		return false, false, nil
 	}
-	return false, false, nil
+	id, err := e.raise(ctx, "rpc", name, url, severity, lat.describe(severity), "p50_ms", lat.p50, "p95_ms", lat.p95)
+	return id != 0, false, err
 }
 
 func (e *Engine) ProcessDappResult(ctx context.Context, name, url string, success bool) (opened, closed bool, err error) {
-	checks, err := e.store.RecentChecks(ctx, "dapp", name, e.cfg.Thresholds.ConsecutiveFailuresForIncident+e.cfg.Thresholds.RecoveriesForClose+2)
+	return e.processReachability(ctx, "dapp", name, url, success, store.SeverityCrit, "Endpoint unreachable or failing.", "Endpoint recovered.")
+}
+
+func (e *Engine) ProcessTCPResult(ctx context.Context, name, address string, success bool) (opened, closed bool, err error) {
+	return e.processReachability(ctx, "tcp", name, address, success, store.SeverityCrit, "Port unreachable or failing.", "Port reachable again.")
+}
+
+func (e *Engine) ProcessNodeMetricsResult(ctx context.Context, name, url string, success bool, severity, summary string) (opened, closed bool, err error) {
+	if severity == "" {
+		severity = store.SeverityCrit
+	}
+	if summary == "" {
+		summary = "Node metrics unavailable or failing."
+	}
+	return e.processReachability(ctx, "node", name, url, success, severity, summary, "Node metrics back within thresholds.")
+}
+
+func (e *Engine) processReachability(ctx context.Context, entityType, name, url string, success bool, severity, openSummary, closeSummary string) (opened, closed bool, err error) {
+	checks, err := e.store.RecentChecks(ctx, entityType, name, e.checkWindow())
 	if err != nil {
 		return false, false, err
 	}
 	openThreshold := e.cfg.Thresholds.ConsecutiveFailuresForIncident
-	closeThreshold := e.cfg.Thresholds.RecoveriesForClose
-
-	hasOpen, openID, err := e.store.HasOpenIncident(ctx, "dapp", name)
+	hasOpen, openID, err := e.store.HasOpenIncident(ctx, entityType, name)
 	if err != nil {
 		return false, false, err
 	}
-
 	if hasOpen {
-		if success {
-			consecutiveSuccess := countConsecutiveSuccess(checks, true)
-			if consecutiveSuccess >= closeThreshold {
-				summary := "Endpoint recovered."
-				if closeErr := e.store.CloseIncident(ctx, openID, summary); closeErr != nil {
-					return false, false, closeErr
-				}
-				_ = e.store.AddIncidentUpdate(ctx, openID, summary)
-				e.alertClosed(ctx, openID)
-				e.log.Info("incident closed", "entity_type", "dapp", "entity_name", name, "incident_id", openID)
-				return false, true, nil
+		if !success && countConsecutiveSuccess(checks, false) >= openThreshold {
+			if err := e.reviewSeverity(ctx, openID, severity, openSummary, true); err != nil {
+				return false, false, err
 			}
 		}
-		return false, false, nil
+		closed, err := e.processOpenIncident(ctx, entityType, name, openID, success, true, checks, closeSummary)
+		return false, closed, err
 	}
-
 	if !success {
 		consecutiveFail := countConsecutiveSuccess(checks, false)
 		if consecutiveFail >= openThreshold {
-			summary := "Endpoint unreachable or failing."
-			id, openErr := e.store.OpenIncident(ctx, "dapp", name, url, store.SeverityCrit, summary)
-			if openErr != nil {
-				return false, false, openErr
+			if errCat := lastErrorCategory(checks); errCat != "" && entityType != "node" {
+				openSummary = strings.TrimSuffix(openSummary, ".") + " (last error: " + errCat + ")."
 			}
-			_ = e.store.AddIncidentUpdate(ctx, id, summary)
-			e.alertOpen(ctx, id)
-			e.log.Info("incident opened", "entity_type", "dapp", "entity_name", name, "incident_id", id)
-			return true, false, nil
+			id, err := e.raise(ctx, entityType, name, url, severity, openSummary)
+			return id != 0, false, err
+		}
+	}
+	opened, err = e.openDegraded(ctx, entityType, name, url, checks)
+	return opened, false, err
+}
+
+// processOpenIncident applies the recovery policy once the entity of an open
+// incident has passed enough consecutive checks and latencyOK, and sends a
+// monitoring incident back to investigating when failures resume. Manual
+// incidents are left to responders.
+func (e *Engine) processOpenIncident(ctx context.Context, entityType, name string, id int64, success, latencyOK bool, checks []store.CheckRow, recoverySummary string) (closed bool, err error) {
+	inc, err := e.store.GetIncident(ctx, id)
+	if err != nil || inc.Manual {
+		return false, err
+	}
+	if !success {
+		if err := e.endMaintenance(ctx, inc); err != nil {
+			return false, err
+		}
+		if err := e.endImpact(ctx, inc); err != nil {
+			return false, err
+		}
+		if inc.Flapping && inc.FlapRecoveredAt != nil {
+			return false, e.recordFlap(ctx, inc)
+		}
+		if inc.State == store.IncidentStateMonitoring && countConsecutiveSuccess(checks, false) >= e.cfg.Thresholds.ConsecutiveFailuresForIncident {
+			return false, e.setState(ctx, inc, store.IncidentStateInvestigating, "Failures resumed while monitoring.")
+		}
+		return false, nil
+	}
+	if countConsecutiveSuccess(checks, true) < e.cfg.Thresholds.RecoveriesForClose || !latencyOK {
+		return false, nil
+	}
+	// A WARN incident is not over while failures stay frequent; a couple of
+	// successes in a row is normal for an intermittent entity.
+	if inc.Severity == store.SeverityWarn {
+		if _, degraded := e.failureRate(checks); degraded {
+			return false, nil
+		}
+	}
+	if hold, err := e.flapHold(ctx, inc); hold || err != nil {
+		return false, err
+	}
+	if inc.Flapping {
+		recoverySummary = fmt.Sprintf("%s Stable again after %d flaps.", recoverySummary, inc.FlapCount)
+	} else if e.cfg.Incidents.OnRecovery == config.RecoveryMonitoring {
+		if inc.State != store.IncidentStateMonitoring {
+			return false, e.setState(ctx, inc, store.IncidentStateMonitoring, strings.TrimSuffix(recoverySummary, ".")+"; monitoring before resolving.")
 		}
+		after := time.Duration(e.cfg.Incidents.ResolveAfterSecs) * time.Second
+		if after <= 0 || e.now().Sub(inc.StateChangedAt) < after {
+			return false, nil
+		}
+	}
+	if err := e.store.CloseIncident(ctx, id, recoverySummary); err != nil {
+		return false, err
 	}
-	return false, false, nil
+	_ = e.store.AddIncidentUpdate(ctx, id, recoverySummary)
+	e.alertClosed(ctx, id)
+	e.log.Info("incident closed", "entity_type", entityType, "entity_name", name, "incident_id", id)
+	return true, e.resolveParent(ctx, inc)
+}
+
+// raise opens an incident unless a maintenance window suppresses it, and
+// announces it unless maintenance mutes it. While a dependency has an open
+// incident it opens as WARN, marked impacted by that incident, without an
+// alert. logAttrs are added to the log line. It returns the new incident's
+// ID, or 0 when suppressed.
+func (e *Engine) raise(ctx context.Context, entityType, name, url, severity, summary string, logAttrs ...any) (int64, error) {
+	m := e.ActiveMaintenance(ctx, entityType, name)
+	if m != nil && m.Mode == config.MaintenanceSuppress {
+		e.log.Debug("incident suppressed by maintenance", "entity_type", entityType, "entity_name", name, "severity", severity)
+		return 0, nil
+	}
+	up := e.upstreamIncident(ctx, entityType, name)
+	if up != nil {
+		severity = store.SeverityWarn
+	}
+	id, err := e.store.OpenIncident(ctx, entityType, name, url, severity, summary)
+	if err != nil {
+		return 0, err
+	}
+	if m != nil {
+		_ = e.store.SetIncidentMaintenance(ctx, id, true)
+		summary = strings.TrimSuffix(summary, ".") + " (during maintenance" + reasonSuffix(m.Reason) + ")."
+	}
+	var upstreamID int64
+	if up != nil {
+		upstreamID = up.ID
+		_ = e.store.SetIncidentImpactedBy(ctx, id, up.ID)
+		summary = strings.TrimSuffix(summary, ".") + fmt.Sprintf(" (impacted by #%d %s/%s).", up.ID, up.EntityType, up.EntityName)
+	}
+	_ = e.store.AddIncidentUpdate(ctx, id, summary)
+	if inc, err := e.store.GetIncident(ctx, id); err == nil {
+		if err := e.correlate(ctx, inc); err != nil {
+			e.log.Warn("correlate incident", "incident_id", id, "err", err)
+		}
+	}
+	e.alertOpen(ctx, id)
+	e.log.Info("incident opened", append([]any{"entity_type", entityType, "entity_name", name, "incident_id", id,
+		"severity", severity, "maintenance", m != nil, "impacted_by", upstreamID}, logAttrs...)...)
+	return id, nil
 }
 
 func (e *Engine) alertOpen(ctx context.Context, id int64) {
@@ -148,7 +257,7 @@ func (e *Engine) alertOpen(ctx context.Context, id int64) {
 		return
 	}
 	inc, err := e.store.GetIncident(ctx, id)
-	if err != nil {
+	if err != nil || e.muted(ctx, inc) {
 		return
 	}
 	e.OnIncidentOpen(ctx, inc)
@@ -159,12 +268,41 @@ func (e *Engine) alertClosed(ctx context.Context, id int64) {
 		return
 	}
 	inc, err := e.store.GetIncident(ctx, id)
-	if err != nil {
+	if err != nil || e.muted(ctx, inc) {
 		return
 	}
 	e.OnIncidentClosed(ctx, inc)
 }
 
+func (e *Engine) alertUpdated(ctx context.Context, id int64, message string) {
+	if e.OnIncidentUpdated == nil {
+		return
+	}
+	inc, err := e.store.GetIncident(ctx, id)
+	if err != nil || e.muted(ctx, inc) {
+		return
+	}
+	e.OnIncidentUpdated(ctx, inc, message)
+}
+
+func (e *Engine) alertSeverity(ctx context.Context, id int64, previous string) {
+	if e.OnSeverityChanged == nil {
+		return
+	}
+	inc, err := e.store.GetIncident(ctx, id)
+	if err != nil || e.muted(ctx, inc) {
+		return
+	}
+	e.OnSeverityChanged(ctx, inc, previous)
+}
+
+func lastErrorCategory(checks []store.CheckRow) string {
+	if len(checks) == 0 || checks[0].Success || !checks[0].ErrorCategory.Valid {
+		return ""
+	}
+	return checks[0].ErrorCategory.String
+}
+
 func countConsecutiveSuccess(checks []store.CheckRow, success bool) int {
 	n := 0
 	for i := range checks {
@@ -3,6 +3,7 @@ package incidents
 import (
 	"context"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gorusys/aptos-guardian/internal/config"
@@ -35,7 +36,7 @@ func TestEngine_ProcessRPCResult_OpenOnConsecutiveFailures(t *testing.T) {
 	for i := 0; i < 3; i++ {
 		_ = st.InsertCheck(ctx, "rpc", "aptoslabs", false, nil, "timeout")
 	}
-	opened, closed, err := eng.ProcessRPCResult(ctx, "aptoslabs", "https://fullnode.mainnet.aptoslabs.com/v1", false, 0)
+	opened, closed, err := eng.ProcessRPCResult(ctx, "aptoslabs", "https://fullnode.mainnet.aptoslabs.com/v1", false)
 	if err != nil {
 		t.Fatalf("ProcessRPCResult: %v", err)
 	}
@@ -49,6 +50,10 @@ func TestEngine_ProcessRPCResult_OpenOnConsecutiveFailures(t *testing.T) {
 	if !hasOpen || id <= 0 {
 		t.Errorf("expected open incident: hasOpen=%v id=%d", hasOpen, id)
 	}
+	inc, _ := st.GetIncident(ctx, id)
+	if !strings.Contains(inc.Summary, "timeout") {
+		t.Errorf("summary should name the error category: %q", inc.Summary)
+	}
 }
 
 func TestEngine_ProcessRPCResult_DedupeNoDoubleOpen(t *testing.T) {
@@ -65,12 +70,12 @@ func TestEngine_ProcessRPCResult_DedupeNoDoubleOpen(t *testing.T) {
 	_, _ = st.EnsureProvider(ctx, "x", "https://x.com")
 	_ = st.InsertCheck(ctx, "rpc", "x", false, nil, "timeout")
 	_ = st.InsertCheck(ctx, "rpc", "x", false, nil, "timeout")
-	opened1, _, _ := eng.ProcessRPCResult(ctx, "x", "https://x.com", false, 0)
+	opened1, _, _ := eng.ProcessRPCResult(ctx, "x", "https://x.com", false)
 	if !opened1 {
 		t.Fatal("expected first open")
 	}
 	_ = st.InsertCheck(ctx, "rpc", "x", false, nil, "timeout")
-	opened2, _, _ := eng.ProcessRPCResult(ctx, "x", "https://x.com", false, 0)
+	opened2, _, _ := eng.ProcessRPCResult(ctx, "x", "https://x.com", false)
 	if opened2 {
 		t.Error("should not open second incident (dedupe)")
 	}
@@ -95,17 +100,17 @@ func TestEngine_ProcessRPCResult_CloseOnRecoveries(t *testing.T) {
 	_, _ = st.EnsureProvider(ctx, "y", "https://y.com")
 	_ = st.InsertCheck(ctx, "rpc", "y", false, nil, "timeout")
 	_ = st.InsertCheck(ctx, "rpc", "y", false, nil, "timeout")
-	opened, _, _ := eng.ProcessRPCResult(ctx, "y", "https://y.com", false, 0)
+	opened, _, _ := eng.ProcessRPCResult(ctx, "y", "https://y.com", false)
 	if !opened {
 		t.Fatal("expected open")
 	}
 	_ = st.InsertCheck(ctx, "rpc", "y", true, int64Ptr(50), "")
-	_, closed1, _ := eng.ProcessRPCResult(ctx, "y", "https://y.com", true, 50)
+	_, closed1, _ := eng.ProcessRPCResult(ctx, "y", "https://y.com", true)
 	if closed1 {
 		t.Error("one success should not close yet")
 	}
 	_ = st.InsertCheck(ctx, "rpc", "y", true, int64Ptr(50), "")
-	_, closed2, _ := eng.ProcessRPCResult(ctx, "y", "https://y.com", true, 50)
+	_, closed2, _ := eng.ProcessRPCResult(ctx, "y", "https://y.com", true)
 	if !closed2 {
 		t.Error("two consecutive successes should close")
 	}
@@ -115,6 +120,36 @@ func TestEngine_ProcessRPCResult_CloseOnRecoveries(t *testing.T) {
 	}
 }
 
+func TestEngine_ProcessTCPResult_OpenAndClose(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	cfg := mustLoadConfig(t)
+	cfg.Thresholds.ConsecutiveFailuresForIncident = 2
+	cfg.Thresholds.RecoveriesForClose = 1
+	eng := NewEngine(st, cfg, nil)
+
+	_ = st.InsertCheck(ctx, "tcp", "p2p", false, nil, "conn_refused")
+	_ = st.InsertCheck(ctx, "tcp", "p2p", false, nil, "conn_refused")
+	opened, _, _ := eng.ProcessTCPResult(ctx, "p2p", "node.example.com:6182", false)
+	if !opened {
+		t.Fatal("expected open")
+	}
+	_, id, _ := st.HasOpenIncident(ctx, "tcp", "p2p")
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.Summary != "Port unreachable or failing (last error: conn_refused)." {
+		t.Errorf("summary = %q", inc.Summary)
+	}
+	_ = st.InsertCheck(ctx, "tcp", "p2p", true, int64Ptr(5), "")
+	_, closed, _ := eng.ProcessTCPResult(ctx, "p2p", "node.example.com:6182", true)
+	if !closed {
+		t.Error("expected close")
+	}
+}
+
 func TestEngine_RecommendedRPCProvider(t *testing.T) {
 	ctx := context.Background()
 	st, err := store.New(ctx, filepath.Join(t.TempDir(), "test.db"))
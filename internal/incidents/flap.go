@@ -0,0 +1,60 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// flapHold decides whether a recovered incident must stay open because its
+// entity is flapping. The first time an entity crosses
+// Incidents.FlapThreshold the incident is marked flapping and one update
+// alert goes out; after that it is held until checks have passed for
+// Incidents.FlapStableSecs.
+func (e *Engine) flapHold(ctx context.Context, inc *store.Incident) (bool, error) {
+	p := e.cfg.Incidents
+	now := e.now()
+	if inc.Flapping {
+		if inc.FlapRecoveredAt == nil {
+			return true, e.store.SetIncidentFlap(ctx, inc.ID, true, inc.FlapCount, &now)
+		}
+		return now.Sub(*inc.FlapRecoveredAt) < time.Duration(p.FlapStableSecs)*time.Second, nil
+	}
+	if p.FlapThreshold <= 0 {
+		return false, nil
+	}
+	window := time.Duration(p.FlapWindowSecs) * time.Second
+	n, err := e.store.CountIncidentsSince(ctx, inc.EntityType, inc.EntityName, now.Add(-window))
+	if err != nil || n < p.FlapThreshold {
+		return false, err
+	}
+	if err := e.store.SetIncidentFlap(ctx, inc.ID, true, n, &now); err != nil {
+		return false, err
+	}
+	msg := fmt.Sprintf("Flapping: %d incidents in the last %s. Holding this incident open until checks pass for %s; further flaps are recorded here without alerts.",
+		n, span(p.FlapWindowSecs), span(p.FlapStableSecs))
+	_ = e.store.AddIncidentUpdate(ctx, inc.ID, msg)
+	e.log.Info("incident flapping", "entity_type", inc.EntityType, "entity_name", inc.EntityName, "incident_id", inc.ID, "flaps", n)
+	e.alertUpdated(ctx, inc.ID, msg)
+	return true, nil
+}
+
+// recordFlap counts a failure after a held recovery of a flapping incident.
+func (e *Engine) recordFlap(ctx context.Context, inc *store.Incident) error {
+	count := inc.FlapCount + 1
+	if err := e.store.SetIncidentFlap(ctx, inc.ID, true, count, nil); err != nil {
+		return err
+	}
+	_ = e.store.AddIncidentUpdate(ctx, inc.ID, fmt.Sprintf("Failing again (%d flaps).", count))
+	e.log.Info("incident flapped", "entity_type", inc.EntityType, "entity_name", inc.EntityName, "incident_id", inc.ID, "flaps", count)
+	return nil
+}
+
+func span(secs int) string {
+	if secs%60 == 0 {
+		return fmt.Sprintf("%d min", secs/60)
+	}
+	return fmt.Sprintf("%d s", secs)
+}
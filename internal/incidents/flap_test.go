@@ -0,0 +1,75 @@
+package incidents
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_FlappingHoldsIncidentOpen(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.FlapThreshold = 3
+	eng.cfg.Incidents.FlapWindowSecs = 3600
+	eng.cfg.Incidents.FlapStableSecs = 600
+	eng.cfg.Thresholds.DegradedFailureRatePct = 0
+	var opens, closes, updates int
+	eng.OnIncidentOpen = func(context.Context, *store.Incident) { opens++ }
+	eng.OnIncidentClosed = func(context.Context, *store.Incident) { closes++ }
+	eng.OnIncidentUpdated = func(context.Context, *store.Incident, string) { updates++ }
+	check := func(success bool) (opened, closed bool) {
+		errCat := ""
+		if !success {
+			errCat = "timeout"
+		}
+		_ = st.InsertCheck(ctx, "tcp", "p2p", success, int64Ptr(5), errCat)
+		opened, closed, err := eng.ProcessTCPResult(ctx, "p2p", "node:6180", success)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return opened, closed
+	}
+	cycle := func() {
+		check(false)
+		check(false)
+		check(true)
+		check(true)
+	}
+
+	cycle()
+	cycle()
+	if opens != 2 || closes != 2 {
+		t.Fatalf("before flapping: opens=%d closes=%d", opens, closes)
+	}
+	cycle()
+	_, id, _ := st.HasOpenIncident(ctx, "tcp", "p2p")
+	inc, _ := st.GetIncident(ctx, id)
+	if !inc.Flapping || inc.FlapCount != 3 {
+		t.Fatalf("third cycle should flap: %+v", inc)
+	}
+	cycle()
+	cycle()
+	if opens != 3 || closes != 2 || updates != 1 {
+		t.Errorf("flapping should suppress alerts: opens=%d closes=%d updates=%d", opens, closes, updates)
+	}
+	inc, _ = st.GetIncident(ctx, id)
+	if inc.FlapCount != 5 {
+		t.Errorf("flap count = %d", inc.FlapCount)
+	}
+	timeline, _ := st.IncidentUpdates(ctx, id)
+	if last := timeline[len(timeline)-1].Message; last != "Failing again (5 flaps)." {
+		t.Errorf("last update = %q", last)
+	}
+
+	eng.now = func() time.Time { return time.Now().Add(15 * time.Minute) }
+	if _, closed := check(true); !closed {
+		t.Fatal("stable flapping incident should close")
+	}
+	inc, _ = st.GetIncident(ctx, id)
+	if !strings.HasSuffix(inc.Summary, "Stable again after 5 flaps.") || closes != 3 {
+		t.Errorf("summary = %q, closes = %d", inc.Summary, closes)
+	}
+}
@@ -0,0 +1,69 @@
+package incidents
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+	"github.com/gorusys/aptos-guardian/internal/util/stats"
+)
+
+// latencyStats summarises the successful checks in a latency window.
+type latencyStats struct {
+	p50, p95 int64
+	samples  int
+}
+
+// windowLatency computes p50 and p95 over the successful checks among the
+// newest window checks (checks are newest first).
+func windowLatency(checks []store.CheckRow, window int) latencyStats {
+	if window > len(checks) {
+		window = len(checks)
+	}
+	var lats []int64
+	for _, c := range checks[:window] {
+		if c.Success && c.LatencyMs.Valid {
+			lats = append(lats, c.LatencyMs.Int64)
+		}
+	}
+	if len(lats) == 0 {
+		return latencyStats{}
+	}
+	sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+	return latencyStats{p50: stats.Percentile(lats, 50), p95: stats.Percentile(lats, 95), samples: len(lats)}
+}
+
+// latencyLevel grades window latency: CRIT when p50 reaches the critical
+// threshold, WARN when p95 reaches the warning threshold. An incident that
+// is already open at a level only drops below it once latency falls under
+// the matching clear threshold.
+func (e *Engine) latencyLevel(s latencyStats, current string) string {
+	if s.samples == 0 {
+		return ""
+	}
+	t := e.cfg.Thresholds
+	critAt, warnAt := t.LatencyCritMS, t.LatencyWarnMS
+	if current == store.SeverityCrit {
+		critAt = t.LatencyCritClearMS
+	}
+	if current != "" {
+		warnAt = t.LatencyWarnClearMS
+	}
+	switch {
+	case s.p50 >= int64(critAt):
+		return store.SeverityCrit
+	case s.p95 >= int64(warnAt):
+		return store.SeverityWarn
+	}
+	return ""
+}
+
+func (s latencyStats) describe(level string) string {
+	switch level {
+	case store.SeverityCrit:
+		return fmt.Sprintf("RPC latency critical (p50 %d ms over %d checks).", s.p50, s.samples)
+	case store.SeverityWarn:
+		return fmt.Sprintf("RPC latency elevated (p95 %d ms over %d checks).", s.p95, s.samples)
+	}
+	return ""
+}
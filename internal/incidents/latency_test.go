@@ -0,0 +1,72 @@
+package incidents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_LatencyWindowAndHysteresis(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	th := &eng.cfg.Thresholds
+	th.LatencyWindow = 20
+	th.LatencyWarnMS, th.LatencyWarnClearMS = 600, 480
+	th.LatencyCritMS, th.LatencyCritClearMS = 1500, 1200
+	check := func(lat int64) (opened, closed bool) {
+		_ = st.InsertCheck(ctx, "rpc", "p", true, int64Ptr(lat), "")
+		opened, closed, err := eng.ProcessRPCResult(ctx, "p", "https://p.example", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return opened, closed
+	}
+
+	for i := 0; i < 19; i++ {
+		check(100)
+	}
+	if opened, _ := check(3000); opened {
+		t.Fatal("a single slow sample should not open an incident")
+	}
+	for i := 0; i < 3; i++ {
+		check(700)
+	}
+	hasOpen, id, _ := st.HasOpenIncident(ctx, "rpc", "p")
+	if !hasOpen {
+		t.Fatal("sustained p95 above warn should open an incident")
+	}
+	if inc, _ := st.GetIncident(ctx, id); inc.Severity != store.SeverityWarn {
+		t.Errorf("severity = %s, want WARN", inc.Severity)
+	}
+
+	// Between the clear and open thresholds: still slow, not recovered.
+	for i := 0; i < 20; i++ {
+		if _, closed := check(500); closed {
+			t.Fatalf("closed at check %d while p95 above clear threshold", i)
+		}
+	}
+	closed := false
+	for i := 0; i < 20 && !closed; i++ {
+		_, closed = check(100)
+	}
+	if !closed {
+		t.Error("incident should close once p95 is under the clear threshold")
+	}
+}
+
+func TestEngine_LatencyCritOnMedian(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Thresholds.LatencyWindow = 4
+	for _, lat := range []int64{2000, 2000} {
+		_ = st.InsertCheck(ctx, "rpc", "p", true, int64Ptr(lat), "")
+	}
+	if opened, _, _ := eng.ProcessRPCResult(ctx, "p", "https://p.example", true); !opened {
+		t.Fatal("expected incident")
+	}
+	_, id, _ := st.HasOpenIncident(ctx, "rpc", "p")
+	if inc, _ := st.GetIncident(ctx, id); inc.Severity != store.SeverityCrit {
+		t.Errorf("severity = %s, want CRIT", inc.Severity)
+	}
+}
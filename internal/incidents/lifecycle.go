@@ -0,0 +1,86 @@
+package incidents
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+var (
+	ErrIncidentNotFound = errors.New("incident not found")
+	ErrInvalidState     = errors.New("invalid incident state")
+	ErrIncidentResolved = errors.New("incident already resolved")
+)
+
+// Transition moves an open incident to state and records message in its
+// timeline. Resolving fires OnIncidentClosed; other states OnIncidentUpdated.
+func (e *Engine) Transition(ctx context.Context, id int64, state, message string) (*store.Incident, error) {
+	if !store.ValidIncidentState(state) {
+		return nil, ErrInvalidState
+	}
+	inc, err := e.openIncident(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if message == "" {
+		message = "Status changed to " + state + "."
+	}
+	if err := e.setState(ctx, inc, state, message); err != nil {
+		return nil, err
+	}
+	return e.store.GetIncident(ctx, id)
+}
+
+// Acknowledge marks an open incident as seen by a responder without changing
+// its state.
+func (e *Engine) Acknowledge(ctx context.Context, id int64, by string) (*store.Incident, error) {
+	inc, err := e.openIncident(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inc.Acknowledged {
+		return inc, nil
+	}
+	if err := e.store.AcknowledgeIncident(ctx, id); err != nil {
+		return nil, err
+	}
+	message := "Acknowledged."
+	if by != "" {
+		message = "Acknowledged by " + by + "."
+	}
+	_ = e.store.AddIncidentUpdate(ctx, id, message)
+	e.alertUpdated(ctx, id, message)
+	e.log.Info("incident acknowledged", "incident_id", id, "by", by)
+	return e.store.GetIncident(ctx, id)
+}
+
+func (e *Engine) openIncident(ctx context.Context, id int64) (*store.Incident, error) {
+	inc, err := e.store.GetIncident(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrIncidentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !inc.Open() {
+		return nil, ErrIncidentResolved
+	}
+	return inc, nil
+}
+
+func (e *Engine) setState(ctx context.Context, inc *store.Incident, state, message string) error {
+	if err := e.store.SetIncidentState(ctx, inc.ID, state); err != nil {
+		return err
+	}
+	_ = e.store.AddIncidentUpdate(ctx, inc.ID, message)
+	e.log.Info("incident state changed", "entity_type", inc.EntityType, "entity_name", inc.EntityName,
+		"incident_id", inc.ID, "from", inc.State, "to", state)
+	if state == store.IncidentStateResolved {
+		e.alertClosed(ctx, inc.ID)
+		return e.resolveParent(ctx, inc)
+	}
+	e.alertUpdated(ctx, inc.ID, message)
+	return nil
+}
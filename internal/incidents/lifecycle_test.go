@@ -0,0 +1,121 @@
+package incidents
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorusys/aptos-guardian/internal/config"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func newTestEngine(t *testing.T) (*Engine, *store.Store) {
+	t.Helper()
+	st, err := store.New(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	cfg := mustLoadConfig(t)
+	cfg.Thresholds.ConsecutiveFailuresForIncident = 2
+	cfg.Thresholds.RecoveriesForClose = 2
+	return NewEngine(st, cfg, nil), st
+}
+
+func TestEngine_Transition(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	var updates []string
+	var closed int
+	eng.OnIncidentUpdated = func(_ context.Context, inc *store.Incident, message string) {
+		updates = append(updates, inc.State+": "+message)
+	}
+	eng.OnIncidentClosed = func(context.Context, *store.Incident) { closed++ }
+	id, _ := st.OpenIncident(ctx, "dapp", "explorer", "https://explorer.aptoslabs.com", store.SeverityCrit, "down")
+
+	if _, err := eng.Transition(ctx, id, "fixed", ""); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("unknown state: err = %v", err)
+	}
+	if _, err := eng.Transition(ctx, id+100, store.IncidentStateIdentified, ""); !errors.Is(err, ErrIncidentNotFound) {
+		t.Errorf("unknown incident: err = %v", err)
+	}
+	inc, err := eng.Transition(ctx, id, store.IncidentStateIdentified, "CDN misconfiguration.")
+	if err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if inc.State != store.IncidentStateIdentified {
+		t.Errorf("state = %q", inc.State)
+	}
+	inc, err = eng.Acknowledge(ctx, id, "alice")
+	if err != nil || !inc.Acknowledged {
+		t.Fatalf("Acknowledge: %v, %+v", err, inc)
+	}
+	if _, err := eng.Transition(ctx, id, store.IncidentStateResolved, "Fixed."); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if _, err := eng.Transition(ctx, id, store.IncidentStateMonitoring, ""); !errors.Is(err, ErrIncidentResolved) {
+		t.Errorf("transition after resolve: err = %v", err)
+	}
+	if len(updates) != 2 || updates[0] != "identified: CDN misconfiguration." || updates[1] != "identified: Acknowledged by alice." {
+		t.Errorf("updates = %q", updates)
+	}
+	if closed != 1 {
+		t.Errorf("closed alerts = %d", closed)
+	}
+	timeline, _ := st.IncidentUpdates(ctx, id)
+	if len(timeline) != 3 || timeline[2].State != store.IncidentStateResolved {
+		t.Errorf("timeline = %+v", timeline)
+	}
+}
+
+func TestEngine_RecoveryMonitoring(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.OnRecovery = config.RecoveryMonitoring
+	eng.cfg.Incidents.ResolveAfterSecs = 0
+
+	for i := 0; i < 2; i++ {
+		_ = st.InsertCheck(ctx, "tcp", "p2p", false, nil, "conn_refused")
+	}
+	if opened, _, _ := eng.ProcessTCPResult(ctx, "p2p", "node:6180", false); !opened {
+		t.Fatal("expected incident to open")
+	}
+	_, id, _ := st.HasOpenIncident(ctx, "tcp", "p2p")
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.State != store.IncidentStateInvestigating {
+		t.Errorf("new incident state = %q", inc.State)
+	}
+
+	for i := 0; i < 2; i++ {
+		_ = st.InsertCheck(ctx, "tcp", "p2p", true, nil, "")
+	}
+	_, closed, err := eng.ProcessTCPResult(ctx, "p2p", "node:6180", true)
+	if err != nil || closed {
+		t.Fatalf("recovery: closed=%v err=%v", closed, err)
+	}
+	inc, _ = st.GetIncident(ctx, id)
+	if inc.State != store.IncidentStateMonitoring {
+		t.Errorf("state after recovery = %q", inc.State)
+	}
+	if _, closed, _ := eng.ProcessTCPResult(ctx, "p2p", "node:6180", true); closed {
+		t.Error("resolved without monitoring_resolve_after_secs")
+	}
+
+	for i := 0; i < 2; i++ {
+		_ = st.InsertCheck(ctx, "tcp", "p2p", false, nil, "timeout")
+	}
+	_, _, _ = eng.ProcessTCPResult(ctx, "p2p", "node:6180", false)
+	inc, _ = st.GetIncident(ctx, id)
+	if inc.State != store.IncidentStateInvestigating {
+		t.Errorf("state after relapse = %q", inc.State)
+	}
+
+	eng.cfg.Incidents.OnRecovery = config.RecoveryResolve
+	for i := 0; i < 2; i++ {
+		_ = st.InsertCheck(ctx, "tcp", "p2p", true, nil, "")
+	}
+	if _, closed, _ := eng.ProcessTCPResult(ctx, "p2p", "node:6180", true); !closed {
+		t.Error("resolve policy should close on recovery")
+	}
+}
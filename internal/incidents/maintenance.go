@@ -0,0 +1,151 @@
+package incidents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gorusys/aptos-guardian/internal/config"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+var (
+	ErrMaintenanceNotFound = errors.New("maintenance window not found")
+	ErrMaintenanceConfig   = errors.New("maintenance window is defined in config")
+	ErrInvalidMaintenance  = errors.New("invalid maintenance window")
+)
+
+// Maintenances lists windows from config and from the store that have not
+// ended yet, earliest start first. Config windows have ID 0.
+func (e *Engine) Maintenances(ctx context.Context) ([]store.Maintenance, error) {
+	now := e.now()
+	var out []store.Maintenance
+	for _, w := range e.cfg.Maintenance {
+		if !w.EndsAt.After(now) {
+			continue
+		}
+		out = append(out, store.Maintenance{
+			Entity: w.Entity, Tag: w.Tag, All: w.All, StartsAt: w.StartsAt, EndsAt: w.EndsAt,
+			Reason: w.Reason, Mode: w.Mode, CreatedBy: "config",
+		})
+	}
+	stored, err := e.store.ListMaintenance(ctx, now)
+	if err != nil {
+		return out, err
+	}
+	out = append(out, stored...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartsAt.Before(out[j].StartsAt) })
+	return out, nil
+}
+
+// ActiveMaintenance returns the window covering the entity now, preferring
+// one that suppresses incidents, or nil.
+func (e *Engine) ActiveMaintenance(ctx context.Context, entityType, name string) *store.Maintenance {
+	windows, _ := e.Maintenances(ctx)
+	now := e.now()
+	var found *store.Maintenance
+	for i := range windows {
+		m := &windows[i]
+		if !m.Active(now) || !e.covers(m, entityType, name) {
+			continue
+		}
+		if m.Mode == config.MaintenanceSuppress {
+			return m
+		}
+		if found == nil {
+			found = m
+		}
+	}
+	return found
+}
+
+// ScheduleMaintenance validates and stores a window created at runtime.
+func (e *Engine) ScheduleMaintenance(ctx context.Context, m store.Maintenance) (*store.Maintenance, error) {
+	w := config.MaintenanceWindow{Entity: m.Entity, Tag: m.Tag, All: m.All, StartsAt: m.StartsAt, EndsAt: m.EndsAt, Mode: m.Mode}
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMaintenance, err)
+	}
+	if !w.EndsAt.After(e.now()) {
+		return nil, fmt.Errorf("%w: ends_at is in the past", ErrInvalidMaintenance)
+	}
+	if typ, name, _ := strings.Cut(w.Entity, "/"); w.Entity != "" {
+		if _, _, ok := e.lookupEntity(typ, name); !ok {
+			return nil, fmt.Errorf("%w: entity %q is not configured", ErrInvalidMaintenance, w.Entity)
+		}
+	}
+	m.Mode = w.Mode
+	id, err := e.store.InsertMaintenance(ctx, &m)
+	if err != nil {
+		return nil, err
+	}
+	m.ID = id
+	e.log.Info("maintenance scheduled", "id", id, "entity", m.Entity, "tag", m.Tag, "all", m.All,
+		"starts_at", m.StartsAt, "ends_at", m.EndsAt, "by", m.CreatedBy)
+	return &m, nil
+}
+
+// CancelMaintenance deletes a window created at runtime.
+func (e *Engine) CancelMaintenance(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrMaintenanceConfig
+	}
+	ok, err := e.store.DeleteMaintenance(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMaintenanceNotFound
+	}
+	e.log.Info("maintenance cancelled", "id", id)
+	return nil
+}
+
+// muted reports whether alerts for the incident are silenced: it opened
+// during maintenance, a window is active now, its parent alerts for it, or
+// an upstream incident explains it.
+func (e *Engine) muted(ctx context.Context, inc *store.Incident) bool {
+	return inc.Maintenance || inc.ParentID != 0 || inc.ImpactedBy != 0 ||
+		e.ActiveMaintenance(ctx, inc.EntityType, inc.EntityName) != nil
+}
+
+// endMaintenance clears the maintenance flag of an incident that is still
+// open after its window ended, and announces it as a normal incident.
+func (e *Engine) endMaintenance(ctx context.Context, inc *store.Incident) error {
+	if !inc.Maintenance || e.ActiveMaintenance(ctx, inc.EntityType, inc.EntityName) != nil {
+		return nil
+	}
+	if err := e.store.SetIncidentMaintenance(ctx, inc.ID, false); err != nil {
+		return err
+	}
+	inc.Maintenance = false
+	_ = e.store.AddIncidentUpdate(ctx, inc.ID, "Maintenance window ended; incident still open.")
+	e.log.Info("incident outlasted maintenance", "entity_type", inc.EntityType, "entity_name", inc.EntityName, "incident_id", inc.ID)
+	e.alertOpen(ctx, inc.ID)
+	return nil
+}
+
+// covers matches family series ("name@ipv6") by their base name.
+func (e *Engine) covers(m *store.Maintenance, entityType, name string) bool {
+	base, _, _ := strings.Cut(name, "@")
+	switch {
+	case m.All:
+		return true
+	case m.Entity != "":
+		return m.Entity == entityType+"/"+base
+	case m.Tag != "":
+		k, v, _ := strings.Cut(m.Tag, "=")
+		_, tags, _ := e.lookupEntity(entityType, base)
+		val, ok := tags[k]
+		return ok && val == v
+	}
+	return false
+}
+
+func reasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return ": " + reason
+}
@@ -0,0 +1,95 @@
+package incidents
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/config"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_MaintenanceSuppressesIncidents(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	now := time.Now()
+	eng.cfg.Maintenance = []config.MaintenanceWindow{{
+		Entity: "dapp/explorer", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), Mode: config.MaintenanceSuppress,
+	}}
+	var opens int
+	eng.OnIncidentOpen = func(context.Context, *store.Incident) { opens++ }
+	for i := 0; i < 3; i++ {
+		_ = st.InsertCheck(ctx, "dapp", "explorer", false, nil, "http_5xx")
+		if opened, _, _ := eng.ProcessDappResult(ctx, "explorer", "https://explorer.example", false); opened {
+			t.Fatal("incident opened during suppressing maintenance")
+		}
+	}
+	// Other entities are not covered.
+	for i := 0; i < 2; i++ {
+		_ = st.InsertCheck(ctx, "dapp", "other", false, nil, "http_5xx")
+		_, _, _ = eng.ProcessDappResult(ctx, "other", "https://other.example", false)
+	}
+	if opens != 1 {
+		t.Errorf("opens = %d", opens)
+	}
+}
+
+func TestEngine_MaintenanceFlagsAndMutes(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.RPCProviders = []config.RPCProvider{{Name: "alchemy", Tags: map[string]string{"tier": "premium"}}}
+	m, err := eng.ScheduleMaintenance(ctx, store.Maintenance{
+		Tag: "tier=premium", StartsAt: time.Now().Add(-time.Minute), EndsAt: time.Now().Add(time.Hour),
+		Mode: config.MaintenanceFlag, Reason: "Provider upgrade", CreatedBy: "alice",
+	})
+	if err != nil {
+		t.Fatalf("ScheduleMaintenance: %v", err)
+	}
+	var opens int
+	eng.OnIncidentOpen = func(context.Context, *store.Incident) { opens++ }
+	for i := 0; i < 2; i++ {
+		_ = st.InsertCheck(ctx, "rpc", "alchemy@ipv6", false, nil, "timeout")
+		_, _, _ = eng.ProcessRPCResult(ctx, "alchemy@ipv6", "https://alchemy.example", false)
+	}
+	hasOpen, id, _ := st.HasOpenIncident(ctx, "rpc", "alchemy@ipv6")
+	if !hasOpen {
+		t.Fatal("maintenance mode should still open an incident")
+	}
+	inc, _ := st.GetIncident(ctx, id)
+	if !inc.Maintenance || opens != 0 {
+		t.Errorf("maintenance = %v, open alerts = %d", inc.Maintenance, opens)
+	}
+
+	// The window ends while the provider is still down: announce it.
+	if err := eng.CancelMaintenance(ctx, m.ID); err != nil {
+		t.Fatalf("CancelMaintenance: %v", err)
+	}
+	if err := eng.CancelMaintenance(ctx, m.ID); err != ErrMaintenanceNotFound {
+		t.Errorf("second cancel: %v", err)
+	}
+	_ = st.InsertCheck(ctx, "rpc", "alchemy@ipv6", false, nil, "timeout")
+	_, _, _ = eng.ProcessRPCResult(ctx, "alchemy@ipv6", "https://alchemy.example", false)
+	inc, _ = st.GetIncident(ctx, id)
+	if inc.Maintenance || opens != 1 {
+		t.Errorf("after window: maintenance = %v, open alerts = %d", inc.Maintenance, opens)
+	}
+}
+
+func TestEngine_ScheduleMaintenanceValidates(t *testing.T) {
+	eng, _ := newTestEngine(t)
+	now := time.Now()
+	bad := []store.Maintenance{
+		{StartsAt: now, EndsAt: now.Add(time.Hour)},
+		{All: true, Entity: "rpc/a", StartsAt: now, EndsAt: now.Add(time.Hour)},
+		{Entity: "rpc", StartsAt: now, EndsAt: now.Add(time.Hour)},
+		{All: true, StartsAt: now, EndsAt: now.Add(-time.Hour)},
+		{All: true, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)},
+		{Entity: "rpc/unknown", StartsAt: now, EndsAt: now.Add(time.Hour)},
+	}
+	for i, m := range bad {
+		if _, err := eng.ScheduleMaintenance(context.Background(), m); !errors.Is(err, ErrInvalidMaintenance) {
+			t.Errorf("case %d: err = %v, want ErrInvalidMaintenance", i, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package incidents
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// AnnouncementEntityType is the entity type of manual incidents that are not
+// tied to a monitored entity; the entity name is free-form.
+const AnnouncementEntityType = "announcement"
+
+var (
+	ErrUnknownEntity   = errors.New("unknown entity")
+	ErrInvalidSeverity = errors.New("invalid severity")
+	ErrIncidentOpen    = errors.New("entity already has an open incident")
+)
+
+// OpenManual opens an incident on behalf of a responder, for a monitored
+// entity or an announcement, and announces it like any other incident.
+// Checks do not re-grade or close it; see SetSeverity and Resolve.
+func (e *Engine) OpenManual(ctx context.Context, entityType, name, severity, summary, by string) (*store.Incident, error) {
+	if !validSeverity(severity) {
+		return nil, ErrInvalidSeverity
+	}
+	url, ok := e.entityURL(entityType, name)
+	if !ok {
+		return nil, ErrUnknownEntity
+	}
+	hasOpen, _, err := e.store.HasOpenIncident(ctx, entityType, name)
+	if err != nil {
+		return nil, err
+	}
+	if hasOpen {
+		return nil, ErrIncidentOpen
+	}
+	id, err := e.store.OpenIncident(ctx, entityType, name, url, severity, summary)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.store.SetIncidentManual(ctx, id, true); err != nil {
+		return nil, err
+	}
+	_ = e.store.AddIncidentUpdate(ctx, id, byPrefix("Opened", by)+summary)
+	e.alertOpen(ctx, id)
+	e.log.Info("incident opened", "entity_type", entityType, "entity_name", name, "incident_id", id,
+		"severity", severity, "manual", true, "by", by)
+	return e.store.GetIncident(ctx, id)
+}
+
+// PostUpdate records a responder's message in an open incident's timeline
+// and fires OnIncidentUpdated.
+func (e *Engine) PostUpdate(ctx context.Context, id int64, message, by string) (*store.Incident, error) {
+	if _, err := e.openIncident(ctx, id); err != nil {
+		return nil, err
+	}
+	message = byPrefix("Update", by) + message
+	_ = e.store.AddIncidentUpdate(ctx, id, message)
+	e.alertUpdated(ctx, id, message)
+	e.log.Info("incident updated", "incident_id", id, "by", by)
+	return e.store.GetIncident(ctx, id)
+}
+
+// SetSeverity changes an open incident's severity at once, without the
+// de-escalation hold checks are subject to.
+func (e *Engine) SetSeverity(ctx context.Context, id int64, severity, reason string) (*store.Incident, error) {
+	if !validSeverity(severity) {
+		return nil, ErrInvalidSeverity
+	}
+	inc, err := e.openIncident(ctx, id)
+	if err != nil || inc.Severity == severity {
+		return inc, err
+	}
+	if err := e.store.SetIncidentSeverity(ctx, id, severity); err != nil {
+		return nil, err
+	}
+	verb := "lowered"
+	if severityRank(severity) > severityRank(inc.Severity) {
+		verb = "raised"
+	}
+	if reason == "" {
+		reason = "set by responder."
+	}
+	_ = e.store.AddSeverityUpdate(ctx, id, inc.Severity, severity, "Severity "+verb+" from "+inc.Severity+" to "+severity+": "+reason)
+	e.log.Info("incident severity changed", "entity_type", inc.EntityType, "entity_name", inc.EntityName,
+		"incident_id", id, "from", inc.Severity, "to", severity)
+	e.alertSeverity(ctx, id, inc.Severity)
+	return e.store.GetIncident(ctx, id)
+}
+
+// Resolve closes an open incident with a resolution message, which becomes
+// its summary, and fires OnIncidentClosed.
+func (e *Engine) Resolve(ctx context.Context, id int64, message, by string) (*store.Incident, error) {
+	inc, err := e.openIncident(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if message == "" {
+		message = "Resolved."
+	}
+	if err := e.store.CloseIncident(ctx, id, message); err != nil {
+		return nil, err
+	}
+	_ = e.store.AddIncidentUpdate(ctx, id, byPrefix("Resolved", by)+message)
+	e.alertClosed(ctx, id)
+	e.log.Info("incident closed", "entity_type", inc.EntityType, "entity_name", inc.EntityName, "incident_id", id, "by", by)
+	if err := e.resolveParent(ctx, inc); err != nil {
+		return nil, err
+	}
+	return e.store.GetIncident(ctx, id)
+}
+
+// entityURL looks up a monitored entity's URL or address. Announcements
+// accept any non-empty name and have none.
+func (e *Engine) entityURL(entityType, name string) (string, bool) {
+	if entityType == AnnouncementEntityType {
+		return "", strings.TrimSpace(name) != ""
+	}
+	url, _, ok := e.lookupEntity(entityType, name)
+	return url, ok
+}
+
+// lookupEntity finds a configured entity and returns its URL or address and
+// its tags.
+func (e *Engine) lookupEntity(entityType, name string) (url string, tags map[string]string, ok bool) {
+	switch entityType {
+	case "rpc":
+		for _, p := range e.cfg.RPCProviders {
+			if p.Name == name {
+				return p.URL, p.Tags, true
+			}
+		}
+	case "dapp":
+		for _, d := range e.cfg.Dapps {
+			if d.Name == name {
+				return d.URL, d.Tags, true
+			}
+		}
+	case "tcp":
+		for _, t := range e.cfg.TCPTargets {
+			if t.Name == name {
+				return t.Address, t.Tags, true
+			}
+		}
+	case "node":
+		for _, n := range e.cfg.NodeMetrics {
+			if n.Name == name {
+				return n.URL, n.Tags, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+func validSeverity(severity string) bool {
+	return severity == store.SeverityWarn || severity == store.SeverityCrit
+}
+
+// byPrefix credits a responder in a timeline message: "Opened by ops: ".
+func byPrefix(action, by string) string {
+	if by == "" {
+		return ""
+	}
+	return action + " by " + by + ": "
+}
@@ -0,0 +1,84 @@
+package incidents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_ManualIncident(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	var events []string
+	eng.OnIncidentOpen = func(_ context.Context, inc *store.Incident) { events = append(events, "open "+inc.Severity) }
+	eng.OnIncidentUpdated = func(_ context.Context, _ *store.Incident, message string) { events = append(events, "update "+message) }
+	eng.OnSeverityChanged = func(_ context.Context, inc *store.Incident, previous string) {
+		events = append(events, "severity "+previous+">"+inc.Severity)
+	}
+	eng.OnIncidentClosed = func(_ context.Context, inc *store.Incident) { events = append(events, "closed "+inc.Summary) }
+
+	if _, err := eng.OpenManual(ctx, "rpc", "missing", store.SeverityWarn, "down", ""); !errors.Is(err, ErrUnknownEntity) {
+		t.Errorf("unknown entity: err = %v", err)
+	}
+	if _, err := eng.OpenManual(ctx, AnnouncementEntityType, " ", store.SeverityWarn, "down", ""); !errors.Is(err, ErrUnknownEntity) {
+		t.Errorf("empty announcement: err = %v", err)
+	}
+	if _, err := eng.OpenManual(ctx, "rpc", "aptoslabs", "INFO", "down", ""); !errors.Is(err, ErrInvalidSeverity) {
+		t.Errorf("bad severity: err = %v", err)
+	}
+	inc, err := eng.OpenManual(ctx, "rpc", "aptoslabs", store.SeverityWarn, "Partner reports elevated errors.", "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inc.Manual || inc.EntityURL == "" {
+		t.Errorf("incident = %+v", inc)
+	}
+	if _, err := eng.OpenManual(ctx, "rpc", "aptoslabs", store.SeverityWarn, "again", ""); !errors.Is(err, ErrIncidentOpen) {
+		t.Errorf("second incident: err = %v", err)
+	}
+
+	// Passing checks neither close nor re-grade a manual incident.
+	for i := 0; i < 3; i++ {
+		_ = st.InsertCheck(ctx, "rpc", "aptoslabs", true, int64Ptr(100), "")
+		if _, closed, err := eng.ProcessRPCResult(ctx, "aptoslabs", "", true); err != nil || closed {
+			t.Fatalf("closed = %v, err = %v", closed, err)
+		}
+	}
+	if _, err := eng.PostUpdate(ctx, inc.ID, "Provider confirmed the issue.", "ops"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.SetSeverity(ctx, inc.ID, store.SeverityCrit, "Wider impact."); err != nil {
+		t.Fatal(err)
+	}
+	inc, err = eng.Resolve(ctx, inc.ID, "Provider rolled back.", "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inc.Open() || inc.Summary != "Provider rolled back." {
+		t.Errorf("resolved incident = %+v", inc)
+	}
+	if _, err := eng.PostUpdate(ctx, inc.ID, "late", ""); !errors.Is(err, ErrIncidentResolved) {
+		t.Errorf("update after resolve: err = %v", err)
+	}
+	want := []string{
+		"open WARN",
+		"update Update by ops: Provider confirmed the issue.",
+		"severity WARN>CRIT",
+		"closed Provider rolled back.",
+	}
+	if strings.Join(events, "|") != strings.Join(want, "|") {
+		t.Errorf("events = %q", events)
+	}
+	updates, _ := st.IncidentUpdates(ctx, inc.ID)
+	if len(updates) != 4 || updates[0].Message != "Opened by ops: Partner reports elevated errors." {
+		t.Errorf("updates = %+v", updates)
+	}
+
+	ann, err := eng.OpenManual(ctx, AnnouncementEntityType, "Petra wallet", store.SeverityCrit, "Signing fails in Petra.", "")
+	if err != nil || ann.EntityType != AnnouncementEntityType || ann.EntityURL != "" {
+		t.Fatalf("announcement = %+v, err = %v", ann, err)
+	}
+}
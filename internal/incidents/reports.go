@@ -0,0 +1,122 @@
+package incidents
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// MatchReport picks the incident a user report is most likely about among
+// open incidents and those resolved within Incidents.ReportLinkWindowSecs,
+// or returns nil. The report URL host is compared with each incident's
+// entity URL and the description is searched for the provider or dApp name.
+// The issue type ("rpc_down", "dapp_error", ...) breaks ties, or links the
+// report when exactly one incident of that entity type is a candidate. An
+// open user-reported incident takes the reports that share its key.
+// Among equal matches open and then newer incidents win.
+func (e *Engine) MatchReport(ctx context.Context, r *store.Report) (*store.Incident, error) {
+	window := time.Duration(e.cfg.Incidents.ReportLinkWindowSecs) * time.Second
+	recent, err := e.store.RecentIncidents(ctx, e.now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	host := urlHost(r.URL)
+	text := strings.ToLower(r.Description)
+	kind := issueEntityType(r.IssueType)
+
+	var best, sameKind *store.Incident
+	bestScore, kindCount := 0, 0
+	for i := range recent {
+		inc := &recent[i]
+		if inc.EntityType == ParentEntityType {
+			continue
+		}
+		score := 0
+		if inc.EntityType == ReportEntityType && hasReportKey(r, inc.EntityName) {
+			score += 4
+		}
+		if host != "" && hostMatches(host, urlHost(inc.EntityURL)) {
+			score += 4
+		}
+		if base, _, _ := strings.Cut(inc.EntityName, "@"); len(base) >= 3 && strings.Contains(text, strings.ToLower(base)) {
+			score += 2
+		}
+		if kind != "" && inc.EntityType == kind {
+			score++
+			kindCount++
+			sameKind = inc
+		}
+		if score > bestScore || (score == bestScore && best != nil && inc.Open() && !best.Open()) {
+			best, bestScore = inc, score
+		}
+	}
+	switch {
+	case bestScore >= 2:
+		return best, nil
+	case kindCount == 1:
+		return sameKind, nil
+	}
+	return nil, nil
+}
+
+// SubmitReport stores a user report linked to the incident MatchReport picks
+// and, when it matches none, counts it toward a report spike. It returns the
+// report's ID; r.IncidentID is set when it was linked.
+func (e *Engine) SubmitReport(ctx context.Context, r *store.Report) (int64, error) {
+	if inc, _ := e.MatchReport(ctx, r); inc != nil {
+		r.IncidentID = sql.NullInt64{Int64: inc.ID, Valid: true}
+	}
+	id, err := e.store.InsertReport(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	r.ID = id
+	if !r.IncidentID.Valid {
+		if _, err := e.ProcessReport(ctx, r); err != nil {
+			e.log.Warn("process report", "report_id", id, "err", err)
+		}
+	}
+	return id, nil
+}
+
+// issueEntityType maps an issue type such as "rpc_down" to the entity type
+// named by its first word, or "".
+func issueEntityType(issueType string) string {
+	word, _, _ := strings.Cut(strings.ToLower(issueType), "_")
+	word, _, _ = strings.Cut(word, "-")
+	switch word {
+	case "rpc", "dapp", "tcp", "node":
+		return word
+	}
+	return ""
+}
+
+// urlHost returns the lowercase host of a URL, a host:port address or a
+// bare host with a path, or "".
+func urlHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		if h, _, err := net.SplitHostPort(raw); err == nil {
+			return strings.ToLower(h)
+		}
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// hostMatches reports whether host is entity or one of its subdomains.
+func hostMatches(host, entity string) bool {
+	return entity != "" && (host == entity || strings.HasSuffix(host, "."+entity))
+}
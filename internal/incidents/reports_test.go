@@ -0,0 +1,72 @@
+package incidents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_MatchReport(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	rpcID, _ := st.OpenIncident(ctx, "rpc", "aptoslabs", "https://fullnode.mainnet.aptoslabs.com/v1", store.SeverityCrit, "down")
+	dappID, _ := st.OpenIncident(ctx, "dapp", "explorer", "https://explorer.aptoslabs.com", store.SeverityCrit, "down")
+	tcpID, _ := st.OpenIncident(ctx, "tcp", "p2p", "node.example.com:6182", store.SeverityCrit, "down")
+
+	cases := []struct {
+		name   string
+		report store.Report
+		want   int64
+	}{
+		{"url host", store.Report{IssueType: "other", URL: "https://explorer.aptoslabs.com/txn/0x1"}, dappID},
+		{"bare host", store.Report{IssueType: "other", URL: "explorer.aptoslabs.com/account/0x1"}, dappID},
+		{"host and port", store.Report{IssueType: "other", URL: "node.example.com:6182"}, tcpID},
+		{"name mention", store.Report{IssueType: "other", Description: "AptosLabs RPC keeps timing out"}, rpcID},
+		{"issue type", store.Report{IssueType: "rpc_down", Description: "wallet cannot connect"}, rpcID},
+		{"no match", store.Report{IssueType: "wallet", Description: "lost my seed phrase"}, 0},
+		{"other host", store.Report{IssueType: "other", URL: "https://aptoslabs.com"}, 0},
+	}
+	for _, c := range cases {
+		inc, err := eng.MatchReport(ctx, &c.report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got int64
+		if inc != nil {
+			got = inc.ID
+		}
+		if got != c.want {
+			t.Errorf("%s: matched #%d, want #%d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestEngine_MatchReportRecentlyResolved(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.ReportLinkWindowSecs = 600
+	id, _ := st.OpenIncident(ctx, "dapp", "explorer", "https://explorer.aptoslabs.com", store.SeverityCrit, "down")
+	_ = st.CloseIncident(ctx, id, "recovered")
+	r := &store.Report{IssueType: "dapp_down", URL: "https://explorer.aptoslabs.com"}
+	if inc, _ := eng.MatchReport(ctx, r); inc == nil || inc.ID != id {
+		t.Errorf("recently resolved incident should match: %+v", inc)
+	}
+	eng.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if inc, _ := eng.MatchReport(ctx, r); inc != nil {
+		t.Errorf("incident resolved before the window should not match: %+v", inc)
+	}
+}
+
+func TestEngine_MatchReportPrefersOpen(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	openID, _ := st.OpenIncident(ctx, "dapp", "explorer", "https://explorer.aptoslabs.com", store.SeverityCrit, "down")
+	closedID, _ := st.OpenIncident(ctx, "dapp", "explorer", "https://explorer.aptoslabs.com", store.SeverityCrit, "down")
+	_ = st.CloseIncident(ctx, closedID, "recovered")
+	inc, _ := eng.MatchReport(ctx, &store.Report{IssueType: "other", URL: "https://explorer.aptoslabs.com"})
+	if inc == nil || inc.ID != openID {
+		t.Errorf("matched %+v, want open #%d", inc, openID)
+	}
+}
@@ -0,0 +1,171 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// ReportEntityType is the entity type of user-reported incidents. Their
+// entity name is the report key, e.g. "issue:rpc_down", "host:app.example"
+// or "wallet:petra".
+const ReportEntityType = "reports"
+
+// reportKeys returns the keys a report is counted under.
+func reportKeys(r *store.Report) []string {
+	var keys []string
+	if v := strings.ToLower(strings.TrimSpace(r.IssueType)); v != "" {
+		keys = append(keys, "issue:"+v)
+	}
+	if v := urlHost(r.URL); v != "" {
+		keys = append(keys, "host:"+v)
+	}
+	if v := strings.ToLower(strings.TrimSpace(r.Wallet)); v != "" {
+		keys = append(keys, "wallet:"+v)
+	}
+	return keys
+}
+
+func hasReportKey(r *store.Report, key string) bool {
+	for _, k := range reportKeys(r) {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func describeReportKey(key string) string {
+	kind, v, _ := strings.Cut(key, ":")
+	switch kind {
+	case "issue":
+		return "for issue type " + v
+	case "host":
+		return "for " + v
+	case "wallet":
+		return "from " + v + " wallet users"
+	}
+	return "for " + key
+}
+
+// ProcessReport opens a WARN user-reported incident for the first key of a
+// stored report (issue type, then url host, then wallet) whose recent reports
+// spike. One burst usually spikes all its keys at once, so at most one
+// incident opens per report. Spikes count distinct reporters, so one client
+// repeating a report cannot open an incident alone. Reports already linked to
+// an incident are explained and count neither in the window nor in the
+// baseline; the ones that trigger the spike are linked to the new incident.
+func (e *Engine) ProcessReport(ctx context.Context, r *store.Report) (bool, error) {
+	p := e.cfg.Incidents
+	if p.ReportSpikeCount <= 0 && p.ReportSpikeFactor <= 0 {
+		return false, nil
+	}
+	now := e.now()
+	window := time.Duration(p.ReportSpikeWindowSecs) * time.Second
+	baseline := time.Duration(p.ReportBaselineSecs) * time.Second
+	reports, err := e.store.ReportsSince(ctx, now.Add(-window-baseline))
+	if err != nil {
+		return false, err
+	}
+	for _, key := range reportKeys(r) {
+		hasOpen, _, err := e.store.HasOpenIncident(ctx, ReportEntityType, key)
+		if err != nil {
+			return false, err
+		}
+		if hasOpen {
+			continue
+		}
+		var recent []int64
+		reporters, before := map[string]bool{}, map[string]bool{}
+		for i := range reports {
+			x := &reports[i]
+			if x.IncidentID.Valid || !hasReportKey(x, key) {
+				continue
+			}
+			if x.CreatedAt.Before(now.Add(-window)) {
+				before[reporterOf(x)] = true
+			} else {
+				recent = append(recent, x.ID)
+				reporters[reporterOf(x)] = true
+			}
+		}
+		expected := float64(len(before)) * window.Seconds() / baseline.Seconds()
+		reason := e.reportSpike(len(reporters), expected)
+		if reason == "" {
+			continue
+		}
+		summary := fmt.Sprintf("User-reported: %d reports from %d reporters %s in the last %s (%s).",
+			len(recent), len(reporters), describeReportKey(key), span(p.ReportSpikeWindowSecs), reason)
+		id, err := e.raise(ctx, ReportEntityType, key, "", store.SeverityWarn, summary,
+			"reports", len(recent), "reporters", len(reporters), "baseline", expected)
+		if err != nil {
+			return false, err
+		}
+		if id == 0 {
+			continue
+		}
+		_ = e.store.LinkReports(ctx, id, recent)
+		return true, nil
+	}
+	return false, nil
+}
+
+// reporterOf returns who sent a report; reports without a Reporter each count
+// as their own.
+func reporterOf(r *store.Report) string {
+	if r.Reporter != "" {
+		return r.Reporter
+	}
+	return fmt.Sprintf("report:%d", r.ID)
+}
+
+// reportSpike says why count distinct reporters in the spike window are a
+// spike given the expected number from the baseline, or returns "".
+func (e *Engine) reportSpike(count int, expected float64) string {
+	p := e.cfg.Incidents
+	if p.ReportSpikeCount > 0 && count >= p.ReportSpikeCount {
+		return fmt.Sprintf("threshold %d", p.ReportSpikeCount)
+	}
+	if p.ReportSpikeFactor > 0 && expected > 0 && count >= p.ReportSpikeMinCount && float64(count) >= p.ReportSpikeFactor*expected {
+		return fmt.Sprintf("%.1fx the usual rate", float64(count)/expected)
+	}
+	return ""
+}
+
+// ReviewReportIncidents closes user-reported incidents once a full spike
+// window has passed without new reports for their key. It runs periodically
+// since no check drives these incidents.
+func (e *Engine) ReviewReportIncidents(ctx context.Context) error {
+	open, err := e.store.OpenIncidentsOfType(ctx, ReportEntityType)
+	if err != nil || len(open) == 0 {
+		return err
+	}
+	window := time.Duration(e.cfg.Incidents.ReportSpikeWindowSecs) * time.Second
+	reports, err := e.store.ReportsSince(ctx, e.now().Add(-window))
+	if err != nil {
+		return err
+	}
+	for _, inc := range open {
+		quiet := true
+		for i := range reports {
+			if hasReportKey(&reports[i], inc.EntityName) {
+				quiet = false
+				break
+			}
+		}
+		if !quiet {
+			continue
+		}
+		summary := fmt.Sprintf("Reports back to normal: none in the last %s.", span(e.cfg.Incidents.ReportSpikeWindowSecs))
+		if err := e.store.CloseIncident(ctx, inc.ID, summary); err != nil {
+			return err
+		}
+		_ = e.store.AddIncidentUpdate(ctx, inc.ID, summary)
+		e.alertClosed(ctx, inc.ID)
+		e.log.Info("incident closed", "entity_type", inc.EntityType, "entity_name", inc.EntityName, "incident_id", inc.ID)
+	}
+	return nil
+}
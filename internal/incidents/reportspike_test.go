@@ -0,0 +1,196 @@
+package incidents
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_ReportSpikeCount(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.ReportSpikeCount = 3
+	eng.cfg.Incidents.ReportSpikeFactor = 0
+	var opened []string
+	eng.OnIncidentOpen = func(_ context.Context, inc *store.Incident) { opened = append(opened, inc.EntityName) }
+	submit := func(r store.Report) bool {
+		id, err := st.InsertReport(ctx, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.ID = id
+		ok, err := eng.ProcessReport(ctx, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	// Reports explained by an incident do not count toward a spike.
+	_, _ = st.InsertReport(ctx, &store.Report{IssueType: "Wallet_Sign", IncidentID: sql.NullInt64{Int64: 99, Valid: true}})
+	if submit(store.Report{IssueType: "wallet_sign", Wallet: "Petra"}) || submit(store.Report{IssueType: "wallet_sign"}) {
+		t.Fatal("opened below the threshold")
+	}
+	if !submit(store.Report{IssueType: "wallet_sign", Wallet: "other"}) {
+		t.Fatal("third report should open an incident")
+	}
+	has, id, _ := st.HasOpenIncident(ctx, ReportEntityType, "issue:wallet_sign")
+	if !has || len(opened) != 1 {
+		t.Fatalf("has=%v opened=%v", has, opened)
+	}
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.Severity != store.SeverityWarn || inc.ReportCount != 3 {
+		t.Errorf("incident = %+v", inc)
+	}
+	if submit(store.Report{IssueType: "wallet_sign"}) {
+		t.Error("an open incident should not be opened again")
+	}
+	if has, _, _ := st.HasOpenIncident(ctx, ReportEntityType, "wallet:petra"); has {
+		t.Error("one wallet report should not open an incident")
+	}
+}
+
+func TestEngine_ReportSpikeFactor(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.ReportSpikeCount = 0
+	eng.cfg.Incidents.ReportSpikeFactor = 5
+	eng.cfg.Incidents.ReportSpikeMinCount = 3
+	eng.cfg.Incidents.ReportSpikeWindowSecs = 900
+	eng.cfg.Incidents.ReportBaselineSecs = 4 * 900
+	now := time.Now()
+	// Baseline: 4 reports over four windows, one per window.
+	for i := 1; i <= 4; i++ {
+		_, _ = st.InsertReport(ctx, &store.Report{IssueType: "other", URL: "https://app.example.xyz", CreatedAt: now.Add(-time.Duration(i)*900*time.Second - time.Minute)})
+	}
+	var ok bool
+	for i := 0; i < 5; i++ {
+		r := store.Report{IssueType: "other", URL: "https://app.example.xyz/swap"}
+		r.ID, _ = st.InsertReport(ctx, &r)
+		var err error
+		if ok, err = eng.ProcessReport(ctx, &r); err != nil {
+			t.Fatal(err)
+		}
+		if ok && i < 4 {
+			t.Fatalf("opened after %d reports, want 5", i+1)
+		}
+	}
+	if !ok {
+		t.Fatal("5x the usual rate should open an incident")
+	}
+	if has, _, _ := st.HasOpenIncident(ctx, ReportEntityType, "issue:other"); !has {
+		t.Error("expected an issue incident")
+	}
+	if has, _, _ := st.HasOpenIncident(ctx, ReportEntityType, "host:app.example.xyz"); has {
+		t.Error("the host spikes with the same reports and should not open a second incident")
+	}
+}
+
+func TestEngine_ReportSpikeOneIncidentPerBurst(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.ReportSpikeCount = 3
+	eng.cfg.Incidents.ReportSpikeFactor = 0
+	for i := 0; i < 5; i++ {
+		r := store.Report{IssueType: "wallet_sign", URL: "https://app.example.xyz/swap", Wallet: "Petra"}
+		r.ID, _ = st.InsertReport(ctx, &r)
+		if _, err := eng.ProcessReport(ctx, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	open, _ := st.ListIncidents(ctx, store.IncidentStateOpen, 100)
+	if len(open) != 1 || open[0].EntityName != "issue:wallet_sign" {
+		t.Fatalf("open incidents = %+v, want exactly one", open)
+	}
+	if open[0].ReportCount != 3 {
+		t.Errorf("linked reports = %d, want the 3 that triggered it", open[0].ReportCount)
+	}
+}
+
+func TestEngine_ReportSpikeDistinctReporters(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.ReportSpikeCount = 3
+	eng.cfg.Incidents.ReportSpikeFactor = 0
+	submit := func(reporter string) bool {
+		r := store.Report{IssueType: "rpc_down", Reporter: reporter}
+		r.ID, _ = st.InsertReport(ctx, &r)
+		ok, err := eng.ProcessReport(ctx, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+	for range 5 {
+		if submit("api:a") {
+			t.Fatal("one reporter repeating a report opened an incident")
+		}
+	}
+	if submit("api:b") {
+		t.Fatal("two reporters are below the threshold")
+	}
+	if !submit("discord:c") {
+		t.Fatal("a third reporter should open an incident")
+	}
+	_, id, _ := st.HasOpenIncident(ctx, ReportEntityType, "issue:rpc_down")
+	if inc, _ := st.GetIncident(ctx, id); inc.ReportCount != 7 {
+		t.Errorf("linked reports = %d, want all 7 in the window", inc.ReportCount)
+	}
+}
+
+func TestEngine_ReviewReportIncidents(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.ReportSpikeWindowSecs = 900
+	var closed int
+	eng.OnIncidentClosed = func(context.Context, *store.Incident) { closed++ }
+	id, _ := st.OpenIncident(ctx, ReportEntityType, "issue:rpc_down", "", store.SeverityWarn, "spike")
+	_, _ = st.InsertReport(ctx, &store.Report{IssueType: "rpc_down"})
+	if err := eng.ReviewReportIncidents(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if inc, _ := st.GetIncident(ctx, id); !inc.Open() {
+		t.Fatal("closed while reports keep coming")
+	}
+	eng.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if err := eng.ReviewReportIncidents(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if inc, _ := st.GetIncident(ctx, id); inc.Open() || closed != 1 {
+		t.Errorf("should close after a quiet window: %+v closed=%d", inc, closed)
+	}
+}
+
+func TestEngine_ReviewReportIncidentsBeyondFirstPage(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.ReportSpikeWindowSecs = 900
+	id, _ := st.OpenIncident(ctx, ReportEntityType, "issue:rpc_down", "", store.SeverityWarn, "spike")
+	old := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	if _, err := st.DB().ExecContext(ctx, `UPDATE incidents SET started_at = ? WHERE id = ?`, old, id); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 120; i++ {
+		_, _ = st.OpenIncident(ctx, "dapp", fmt.Sprintf("d%d", i), "", store.SeverityCrit, "down")
+	}
+	if err := eng.ReviewReportIncidents(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if inc, _ := st.GetIncident(ctx, id); inc.Open() {
+		t.Error("a quiet report incident behind 120 newer incidents should close")
+	}
+}
+
+func TestEngine_MatchReportToReportIncident(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	id, _ := st.OpenIncident(ctx, ReportEntityType, "wallet:petra", "", store.SeverityWarn, "spike")
+	inc, err := eng.MatchReport(ctx, &store.Report{IssueType: "other", Wallet: "Petra"})
+	if err != nil || inc == nil || inc.ID != id {
+		t.Errorf("MatchReport = %+v, %v", inc, err)
+	}
+}
@@ -0,0 +1,59 @@
+package incidents
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func severityRank(severity string) int {
+	switch severity {
+	case store.SeverityCrit:
+		return 2
+	case store.SeverityWarn:
+		return 1
+	}
+	return 0
+}
+
+// reviewSeverity moves an open incident to the severity the latest check
+// calls for. Escalation happens at once. De-escalation waits until the
+// current severity has held for Incidents.DeescalateAfterSecs (no hold when
+// -1) and the caller reports the lower level as steady. An empty desired
+// severity leaves the incident alone, as do manual incidents, and an
+// incident impacted by an upstream one is not escalated.
+func (e *Engine) reviewSeverity(ctx context.Context, id int64, desired, reason string, steady bool) error {
+	if desired == "" {
+		return nil
+	}
+	inc, err := e.store.GetIncident(ctx, id)
+	if err != nil {
+		return err
+	}
+	if desired == inc.Severity || inc.Manual {
+		return nil
+	}
+	escalate := severityRank(desired) > severityRank(inc.Severity)
+	if escalate && inc.ImpactedBy != 0 {
+		return nil
+	}
+	if !escalate {
+		hold := time.Duration(max(e.cfg.Incidents.DeescalateAfterSecs, 0)) * time.Second
+		if !steady || e.now().Sub(inc.SeverityChangedAt) < hold {
+			return nil
+		}
+	}
+	if err := e.store.SetIncidentSeverity(ctx, id, desired); err != nil {
+		return err
+	}
+	verb := "lowered"
+	if escalate {
+		verb = "raised"
+	}
+	_ = e.store.AddSeverityUpdate(ctx, id, inc.Severity, desired, "Severity "+verb+" from "+inc.Severity+" to "+desired+": "+reason)
+	e.log.Info("incident severity changed", "entity_type", inc.EntityType, "entity_name", inc.EntityName,
+		"incident_id", id, "from", inc.Severity, "to", desired)
+	e.alertSeverity(ctx, id, inc.Severity)
+	return nil
+}
@@ -0,0 +1,81 @@
+package incidents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_EscalatesLatencyIncidentWhenDown(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	var changes []string
+	eng.OnSeverityChanged = func(_ context.Context, inc *store.Incident, previous string) {
+		changes = append(changes, previous+"->"+inc.Severity)
+	}
+	eng.cfg.Thresholds.LatencyWindow = 4
+	_ = st.InsertCheck(ctx, "rpc", "p", true, int64Ptr(800), "")
+	_ = st.InsertCheck(ctx, "rpc", "p", true, int64Ptr(800), "")
+	if opened, _, _ := eng.ProcessRPCResult(ctx, "p", "https://p.example", true); !opened {
+		t.Fatal("expected WARN latency incident")
+	}
+	_, id, _ := st.HasOpenIncident(ctx, "rpc", "p")
+
+	_ = st.InsertCheck(ctx, "rpc", "p", false, nil, "timeout")
+	_, _, _ = eng.ProcessRPCResult(ctx, "p", "https://p.example", false)
+	if inc, _ := st.GetIncident(ctx, id); inc.Severity != store.SeverityWarn {
+		t.Errorf("one failure should not escalate, severity = %s", inc.Severity)
+	}
+	_ = st.InsertCheck(ctx, "rpc", "p", false, nil, "timeout")
+	_, _, _ = eng.ProcessRPCResult(ctx, "p", "https://p.example", false)
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.Severity != store.SeverityCrit {
+		t.Errorf("severity = %s, want CRIT", inc.Severity)
+	}
+	if len(changes) != 1 || changes[0] != "WARN->CRIT" {
+		t.Errorf("alerts = %q", changes)
+	}
+	updates, _ := st.IncidentUpdates(ctx, id)
+	if last := updates[len(updates)-1].Message; last != "Severity raised from WARN to CRIT: RPC unreachable or failing." {
+		t.Errorf("last update = %q", last)
+	}
+}
+
+func TestEngine_DeescalationIsThrottled(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.Incidents.DeescalateAfterSecs = 300
+	var changes []string
+	eng.OnSeverityChanged = func(_ context.Context, inc *store.Incident, previous string) {
+		changes = append(changes, previous+"->"+inc.Severity)
+	}
+	fail := func(severity string) {
+		_ = st.InsertCheck(ctx, "node", "n1", false, nil, "threshold")
+		_, _, _ = eng.ProcessNodeMetricsResult(ctx, "n1", "http://n1:9101/metrics", false, severity, "Sync lag.")
+	}
+	fail(store.SeverityCrit)
+	fail(store.SeverityCrit)
+	_, id, _ := st.HasOpenIncident(ctx, "node", "n1")
+	if id == 0 {
+		t.Fatal("expected open incident")
+	}
+
+	fail(store.SeverityWarn)
+	if inc, _ := st.GetIncident(ctx, id); inc.Severity != store.SeverityCrit {
+		t.Errorf("de-escalated before hold elapsed: %s", inc.Severity)
+	}
+	eng.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	fail(store.SeverityWarn)
+	if inc, _ := st.GetIncident(ctx, id); inc.Severity != store.SeverityWarn {
+		t.Errorf("severity = %s, want WARN after hold", inc.Severity)
+	}
+	fail(store.SeverityCrit)
+	if inc, _ := st.GetIncident(ctx, id); inc.Severity != store.SeverityCrit {
+		t.Errorf("severity = %s, want immediate escalation", inc.Severity)
+	}
+	if len(changes) != 2 || changes[0] != "CRIT->WARN" || changes[1] != "WARN->CRIT" {
+		t.Errorf("alerts = %q", changes)
+	}
+}
@@ -0,0 +1,168 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/config"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+// SLOEntityType is the entity type of burn-rate incidents; the entity name
+// is the SLO name.
+const SLOEntityType = "slo"
+
+// Burn-rate alerting follows the multiwindow rules of the Google SRE
+// workbook: a fast burn spends 2% of the error budget in an hour, a slow burn
+// 5% in six hours. Each needs its short window to agree, so an alert clears
+// soon after the burning stops.
+var burnAlerts = []struct {
+	severity    string
+	spent       float64
+	long, short time.Duration
+}{
+	{store.SeverityCrit, 0.02, time.Hour, 5 * time.Minute},
+	{store.SeverityWarn, 0.05, 6 * time.Hour, 30 * time.Minute},
+}
+
+// burnThreshold is the burn rate at which spent of the budget of a
+// windowDays SLO goes within long: 14.4 for the fast burn of a 30-day SLO,
+// 3.36 for a 7-day one.
+func burnThreshold(spent float64, long time.Duration, windowDays int) float64 {
+	return spent * float64(windowDays) * 24 * float64(time.Hour) / float64(long)
+}
+
+// BurnWindows are the windows burn rates are reported for.
+var BurnWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+// SLOStatus is an SLO evaluated against the stored checks.
+type SLOStatus struct {
+	config.SLO
+	// Good and Total count checks over the SLO window.
+	Good, Total int
+	// SLI is the percentage of good checks, 100 without checks.
+	SLI float64
+	// BudgetRemaining is the share of the error budget left: 1 untouched,
+	// 0 spent, negative when the objective is missed.
+	BudgetRemaining float64
+	// BurnRates holds the burn rate for each of BurnWindows: how many times
+	// faster than sustainable the budget is being spent.
+	BurnRates []float64
+	// Alert is the severity of the burn-rate alert that currently holds, or "".
+	Alert string
+}
+
+// SLOStatuses evaluates every configured SLO.
+func (e *Engine) SLOStatuses(ctx context.Context) ([]SLOStatus, error) {
+	out := make([]SLOStatus, 0, len(e.cfg.SLOs))
+	for _, slo := range e.cfg.SLOs {
+		st, err := e.sloStatus(ctx, slo)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (e *Engine) sloStatus(ctx context.Context, slo config.SLO) (SLOStatus, error) {
+	st := SLOStatus{SLO: slo, SLI: 100, BudgetRemaining: 1}
+	entityType, name, _ := strings.Cut(slo.Entity, "/")
+	now := e.now()
+	budget := 1 - slo.Objective/100
+	burn := func(window time.Duration) (float64, error) {
+		good, total, err := e.store.CheckCounts(ctx, entityType, name, now.Add(-window), slo.LatencyMS)
+		if err != nil || total == 0 {
+			return 0, err
+		}
+		return float64(total-good) / float64(total) / budget, nil
+	}
+	var err error
+	st.Good, st.Total, err = e.store.CheckCounts(ctx, entityType, name, now.AddDate(0, 0, -slo.WindowDays), slo.LatencyMS)
+	if err != nil {
+		return st, err
+	}
+	if st.Total > 0 {
+		st.SLI = 100 * float64(st.Good) / float64(st.Total)
+		st.BudgetRemaining = 1 - float64(st.Total-st.Good)/float64(st.Total)/budget
+	}
+	rates := make(map[time.Duration]float64)
+	for _, w := range BurnWindows {
+		r, err := burn(w)
+		if err != nil {
+			return st, err
+		}
+		rates[w] = r
+		st.BurnRates = append(st.BurnRates, r)
+	}
+	for _, a := range burnAlerts {
+		rate := burnThreshold(a.spent, a.long, slo.WindowDays)
+		if rates[a.long] >= rate && rates[a.short] >= rate {
+			st.Alert = a.severity
+			break
+		}
+	}
+	return st, nil
+}
+
+// ReviewSLOs opens, re-grades and closes burn-rate incidents for every SLO
+// and returns the statuses it evaluated. It runs periodically since burn
+// rates also change while no checks arrive.
+func (e *Engine) ReviewSLOs(ctx context.Context) ([]SLOStatus, error) {
+	statuses, err := e.SLOStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range statuses {
+		hasOpen, id, err := e.store.HasOpenIncident(ctx, SLOEntityType, st.Name)
+		if err != nil {
+			return statuses, err
+		}
+		summary := st.describe()
+		switch {
+		case hasOpen && st.Alert == "":
+			const closeSummary = "Error budget burn back within limits."
+			if err := e.store.CloseIncident(ctx, id, closeSummary); err != nil {
+				return statuses, err
+			}
+			_ = e.store.AddIncidentUpdate(ctx, id, closeSummary)
+			e.alertClosed(ctx, id)
+			e.log.Info("incident closed", "entity_type", SLOEntityType, "entity_name", st.Name, "incident_id", id)
+		case hasOpen:
+			if err := e.reviewSeverity(ctx, id, st.Alert, summary, true); err != nil {
+				return statuses, err
+			}
+		case st.Alert != "":
+			if _, err := e.raise(ctx, SLOEntityType, st.Name, "", st.Alert, summary, "budget_remaining", st.BudgetRemaining); err != nil {
+				return statuses, err
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// describe summarises a burning SLO for incident summaries.
+func (st SLOStatus) describe() string {
+	for _, a := range burnAlerts {
+		if a.severity != st.Alert {
+			continue
+		}
+		for i, w := range BurnWindows {
+			if w == a.long {
+				return fmt.Sprintf("SLO %s burning error budget %.1fx over %s (objective %g%% over %d days, %.0f%% of budget left).",
+					st.Name, st.BurnRates[i], BurnWindowLabel(w), st.Objective, st.WindowDays, 100*st.BudgetRemaining)
+			}
+		}
+	}
+	return fmt.Sprintf("SLO %s: %.0f%% of error budget left.", st.Name, 100*st.BudgetRemaining)
+}
+
+// BurnWindowLabel renders a burn window as 5m, 1h or 6h.
+func BurnWindowLabel(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
@@ -0,0 +1,118 @@
+package incidents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/config"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestEngine_SLOStatuses(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.SLOs = []config.SLO{
+		{Name: "avail", Entity: "rpc/aptoslabs", Objective: 90, WindowDays: 30},
+		{Name: "fast", Entity: "rpc/aptoslabs", Objective: 50, WindowDays: 30, LatencyMS: 500},
+	}
+	for i := 0; i < 19; i++ {
+		_ = st.InsertCheck(ctx, "rpc", "aptoslabs", true, int64Ptr(int64(100*i)), "")
+	}
+	_ = st.InsertCheck(ctx, "rpc", "aptoslabs", false, nil, "timeout")
+
+	statuses, err := eng.SLOStatuses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avail, fast := statuses[0], statuses[1]
+	if avail.Good != 19 || avail.Total != 20 || avail.SLI != 95 {
+		t.Errorf("avail = %d/%d, sli %v", avail.Good, avail.Total, avail.SLI)
+	}
+	if got := avail.BudgetRemaining; got < 0.49 || got > 0.51 {
+		t.Errorf("avail budget remaining = %v, want 0.5", got)
+	}
+	if got := avail.BurnRates[0]; got < 0.49 || got > 0.51 {
+		t.Errorf("avail 5m burn = %v, want 0.5", got)
+	}
+	if avail.Alert != "" {
+		t.Errorf("avail alert = %q", avail.Alert)
+	}
+	// Only checks at 0..500 ms are good: 6 of 20.
+	if fast.Good != 6 || fast.BudgetRemaining >= 0 {
+		t.Errorf("fast = %d good, budget %v", fast.Good, fast.BudgetRemaining)
+	}
+}
+
+func TestEngine_ReviewSLOs(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	eng.cfg.SLOs = []config.SLO{{Name: "avail", Entity: "rpc/aptoslabs", Objective: 99, WindowDays: 30}}
+	var opened, closed int
+	eng.OnIncidentOpen = func(context.Context, *store.Incident) { opened++ }
+	eng.OnIncidentClosed = func(context.Context, *store.Incident) { closed++ }
+
+	if _, err := eng.ReviewSLOs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if has, _, _ := st.HasOpenIncident(ctx, SLOEntityType, "avail"); has {
+		t.Fatal("opened without checks")
+	}
+	for i := 0; i < 10; i++ {
+		_ = st.InsertCheck(ctx, "rpc", "aptoslabs", i%2 == 0, int64Ptr(100), "")
+	}
+	statuses, err := eng.ReviewSLOs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].Alert != store.SeverityCrit {
+		t.Errorf("alert = %q, want CRIT", statuses[0].Alert)
+	}
+	has, id, _ := st.HasOpenIncident(ctx, SLOEntityType, "avail")
+	if !has || opened != 1 {
+		t.Fatalf("burn should open an incident (opened %d)", opened)
+	}
+	inc, _ := st.GetIncident(ctx, id)
+	if inc.Severity != store.SeverityCrit {
+		t.Errorf("severity = %s", inc.Severity)
+	}
+
+	// Seven hours on, every burn window is past the failures.
+	eng.now = func() time.Time { return time.Now().Add(7 * time.Hour) }
+	statuses, err = eng.ReviewSLOs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].Alert != "" || statuses[0].BudgetRemaining >= 0 {
+		t.Errorf("status = alert %q, budget %v", statuses[0].Alert, statuses[0].BudgetRemaining)
+	}
+	if has, _, _ := st.HasOpenIncident(ctx, SLOEntityType, "avail"); has || closed != 1 {
+		t.Errorf("incident should close once the burn stops (closed %d)", closed)
+	}
+}
+
+func TestEngine_SLOBurnThresholdsScaleWithWindow(t *testing.T) {
+	ctx := context.Background()
+	eng, st := newTestEngine(t)
+	// 4 of 10 checks fail against a 10% budget: a burn rate of 4, under the
+	// 30-day thresholds but past the 7-day fast-burn threshold of 3.36.
+	for i := 0; i < 10; i++ {
+		_ = st.InsertCheck(ctx, "rpc", "aptoslabs", i >= 4, int64Ptr(100), "")
+	}
+	for _, tc := range []struct {
+		days int
+		want string
+	}{
+		{30, ""},
+		{7, store.SeverityCrit},
+	} {
+		eng.cfg.SLOs = []config.SLO{{Name: "avail", Entity: "rpc/aptoslabs", Objective: 90, WindowDays: tc.days}}
+		statuses, err := eng.SLOStatuses(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := statuses[0].Alert; got != tc.want {
+			t.Errorf("window %d days: alert = %q, want %q", tc.days, got, tc.want)
+		}
+	}
+}
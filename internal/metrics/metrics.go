@@ -20,6 +20,48 @@ var (
 		},
 		[]string{"entity_type", "name"},
 	)
+	CheckErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aptos_guardian_check_errors_total",
+			Help: "Failed checks by error category",
+		},
+		[]string{"entity_type", "name", "category"},
+	)
+	NodeMetric = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_node_metric",
+			Help: "Last scraped value of a node health series",
+		},
+		[]string{"name", "series"},
+	)
+	CircuitOpen = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_circuit_open",
+			Help: "1 while an entity is only probed at the reduced circuit-open rate",
+		},
+		[]string{"entity_type", "name"},
+	)
+	ProviderRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aptos_guardian_provider_requests_total",
+			Help: "HTTP requests sent to each RPC provider, including every sub-request of a check",
+		},
+		[]string{"provider"},
+	)
+	RequestBudgetRemaining = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_request_budget_remaining",
+			Help: "Requests left in the provider's daily budget (UTC day)",
+		},
+		[]string{"provider"},
+	)
+	RequestBudgetThrottled = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_request_budget_throttled",
+			Help: "1 while checks of the provider are slowed down to stay within its daily budget",
+		},
+		[]string{"provider"},
+	)
 	IncidentsOpen = promauto.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "aptos_guardian_incidents_open",
@@ -32,6 +74,34 @@ var (
 			Help: "Total number of user reports submitted",
 		},
 	)
+	SLOSLI = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_slo_sli_ratio",
+			Help: "Share of good checks over the SLO window",
+		},
+		[]string{"slo"},
+	)
+	SLOObjective = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_slo_objective_ratio",
+			Help: "SLO objective as a ratio",
+		},
+		[]string{"slo"},
+	)
+	SLOErrorBudgetRemaining = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_slo_error_budget_remaining_ratio",
+			Help: "Share of the SLO error budget left; negative once the objective is missed",
+		},
+		[]string{"slo"},
+	)
+	SLOBurnRate = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aptos_guardian_slo_burn_rate",
+			Help: "Error budget burn rate over the window; 1 spends the budget exactly over the SLO window",
+		},
+		[]string{"slo", "window"},
+	)
 	BuildInfo = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "aptos_guardian_build_info",
@@ -41,15 +111,44 @@ var (
 	)
 )
 
-func RecordCheck(entityType, name string, success bool, latencyMs int64) {
+func RecordCheck(entityType, name string, success bool, latencyMs int64, errorCategory string) {
 	if success {
 		CheckSuccess.WithLabelValues(entityType, name).Set(1)
 	} else {
 		CheckSuccess.WithLabelValues(entityType, name).Set(0)
+		if errorCategory == "" {
+			errorCategory = "unknown"
+		}
+		CheckErrors.WithLabelValues(entityType, name, errorCategory).Inc()
 	}
 	LatencyMs.WithLabelValues(entityType, name).Set(float64(latencyMs))
 }
 
+func SetNodeMetric(name, series string, value float64) {
+	NodeMetric.WithLabelValues(name, series).Set(value)
+}
+
+func SetCircuitOpen(entityType, name string, open bool) {
+	v := 0.0
+	if open {
+		v = 1
+	}
+	CircuitOpen.WithLabelValues(entityType, name).Set(v)
+}
+
+func AddProviderRequests(provider string, n int) {
+	ProviderRequests.WithLabelValues(provider).Add(float64(n))
+}
+
+func SetRequestBudget(provider string, remaining int, throttled bool) {
+	RequestBudgetRemaining.WithLabelValues(provider).Set(float64(remaining))
+	v := 0.0
+	if throttled {
+		v = 1
+	}
+	RequestBudgetThrottled.WithLabelValues(provider).Set(v)
+}
+
 func SetIncidentsOpen(n float64) {
 	IncidentsOpen.Set(n)
 }
@@ -58,6 +157,16 @@ func IncReportsTotal() {
 	ReportsTotal.Inc()
 }
 
+func SetSLO(name string, sli, objective, budgetRemaining float64) {
+	SLOSLI.WithLabelValues(name).Set(sli)
+	SLOObjective.WithLabelValues(name).Set(objective)
+	SLOErrorBudgetRemaining.WithLabelValues(name).Set(budgetRemaining)
+}
+
+func SetSLOBurnRate(name, window string, rate float64) {
+	SLOBurnRate.WithLabelValues(name, window).Set(rate)
+}
+
 func SetBuildInfo(version, commit, date string) {
 	BuildInfo.WithLabelValues(version, commit, date).Set(1)
 }
@@ -2,11 +2,16 @@ package metrics
 
 import (
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestRecordCheck(t *testing.T) {
-	RecordCheck("rpc", "aptoslabs", true, 100)
-	RecordCheck("dapp", "explorer", false, 0)
+	RecordCheck("rpc", "aptoslabs", true, 100, "")
+	RecordCheck("dapp", "explorer", false, 0, "dns")
+	if got := testutil.ToFloat64(CheckErrors.WithLabelValues("dapp", "explorer", "dns")); got != 1 {
+		t.Errorf("check_errors_total{dns} = %v, want 1", got)
+	}
 }
 
 func TestSetBuildInfo(t *testing.T) {
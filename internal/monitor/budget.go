@@ -0,0 +1,178 @@
+package monitor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/metrics"
+)
+
+// BudgetStatus is a snapshot of one provider's daily request budget.
+type BudgetStatus struct {
+	Provider  string
+	Daily     int
+	Used      int
+	Remaining int
+	// Throttled is true while checks are spaced out to stay within the budget.
+	Throttled   bool
+	MinInterval time.Duration
+}
+
+// requestBudget counts the requests sent to one RPC provider per UTC day.
+// The family series of a provider all draw from the same budget.
+type requestBudget struct {
+	mu        sync.Mutex
+	provider  string
+	daily     int
+	perCheck  int
+	entities  int
+	day       string
+	used      int
+	throttled bool
+	// logged is the throttled state last written to the log.
+	logged    bool
+	lastCheck map[string]time.Time
+}
+
+func newRequestBudget(provider string, daily, perCheck int) *requestBudget {
+	return &requestBudget{provider: provider, daily: daily, perCheck: perCheck, lastCheck: make(map[string]time.Time)}
+}
+
+func (b *requestBudget) roll(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if day != b.day {
+		b.day = day
+		b.used = 0
+	}
+}
+
+// minGap spreads the remaining budget evenly over the rest of the UTC day.
+func (b *requestBudget) minGap(now time.Time, remaining int) time.Duration {
+	if remaining <= 0 {
+		return 24 * time.Hour
+	}
+	u := now.UTC()
+	left := time.Date(u.Year(), u.Month(), u.Day()+1, 0, 0, 0, 0, time.UTC).Sub(u)
+	return time.Duration(float64(left) * float64(b.perCheck*b.entities) / float64(remaining))
+}
+
+// reservation is the share of a day's budget allow set aside for one check.
+type reservation struct {
+	day string
+	n   int
+}
+
+// allow reports whether entity may be checked now without running the
+// provider past its daily budget at the current pace. An allowed check
+// reserves perCheck requests at once, so the family series of a provider,
+// checked concurrently, cannot all pass on the same remaining budget; record
+// settles the reservation with the requests actually sent.
+func (b *requestBudget) allow(entity string, now time.Time, interval time.Duration) (reservation, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(now)
+	if b.daily <= 0 {
+		return reservation{}, true
+	}
+	remaining := b.daily - b.used
+	if remaining < b.perCheck {
+		b.throttled = true
+		return reservation{}, false
+	}
+	gap := b.minGap(now, remaining)
+	b.throttled = gap > interval
+	if b.throttled && now.Sub(b.lastCheck[entity]) < gap {
+		return reservation{}, false
+	}
+	b.lastCheck[entity] = now
+	b.used += b.perCheck
+	return reservation{day: b.day, n: b.perCheck}, true
+}
+
+func (b *requestBudget) record(res reservation, n int, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(now)
+	if res.day == b.day {
+		b.used -= res.n
+	}
+	b.used += n
+	if n > b.perCheck {
+		b.perCheck = n
+	}
+}
+
+func (b *requestBudget) status(now time.Time) BudgetStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(now)
+	st := BudgetStatus{Provider: b.provider, Daily: b.daily, Used: b.used, Throttled: b.throttled}
+	if b.daily > 0 {
+		st.Remaining = max(b.daily-b.used, 0)
+		st.MinInterval = b.minGap(now, st.Remaining).Round(time.Second)
+	}
+	return st
+}
+
+// seedBudgets counts the RPC checks already stored today, for every series
+// of a provider, toward its budget so a restart does not hand out the day's
+// quota again. Each check is counted at perCheck requests, which errs on the
+// side of the budget for checks that stopped early.
+func (r *Runner) seedBudgets(ctx context.Context) error {
+	now := r.now()
+	u := now.UTC()
+	day := time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, time.UTC)
+	for name, b := range r.rpcBudgets {
+		if b.daily <= 0 {
+			continue
+		}
+		_, n, err := r.store.CheckCounts(ctx, "rpc", name, day, 0)
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.roll(now)
+		b.used += n * b.perCheck
+		b.mu.Unlock()
+	}
+	return nil
+}
+
+// RequestBudgets reports today's request usage for every RPC provider.
+func (r *Runner) RequestBudgets() []BudgetStatus {
+	seen := make(map[*requestBudget]bool)
+	var out []BudgetStatus
+	now := r.now()
+	for _, b := range r.rpcBudgets {
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		out = append(out, b.status(now))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// reportBudget exports the provider's remaining budget and logs when checks
+// slow down to save requests or return to the configured interval.
+func (r *Runner) reportBudget(b *requestBudget) {
+	st := b.status(r.now())
+	if st.Daily <= 0 {
+		return
+	}
+	metrics.SetRequestBudget(st.Provider, st.Remaining, st.Throttled)
+	b.mu.Lock()
+	changed := b.logged != st.Throttled
+	b.logged = st.Throttled
+	b.mu.Unlock()
+	switch {
+	case changed && st.Throttled:
+		r.log.Warn("request budget low, slowing rpc checks", "provider", st.Provider,
+			"used", st.Used, "daily_budget", st.Daily, "min_interval", st.MinInterval)
+	case changed:
+		r.log.Info("request budget ok, rpc checks back to normal interval", "provider", st.Provider)
+	}
+}
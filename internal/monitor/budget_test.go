@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/config"
+	"github.com/gorusys/aptos-guardian/internal/store"
+)
+
+func TestRequestBudget(t *testing.T) {
+	b := newRequestBudget("premium", 1000, 2)
+	b.entities = 1
+	// Noon UTC: 12h left, 20s interval at 2 requests per check needs 4320
+	// requests, so the 1000 budget forces checks about 86s apart.
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	res, ok := b.allow("premium", now, 20*time.Second)
+	if !ok {
+		t.Fatal("first check not allowed")
+	}
+	if st := b.status(now); st.Used != 2 {
+		t.Fatalf("allow should reserve a check's requests: used = %d", st.Used)
+	}
+	b.record(res, 2, now)
+	st := b.status(now)
+	if !st.Throttled || st.Used != 2 || st.Remaining != 998 {
+		t.Fatalf("status = %+v", st)
+	}
+	if _, ok := b.allow("premium", now.Add(20*time.Second), 20*time.Second); ok {
+		t.Error("allowed a check at the normal interval while throttled")
+	}
+	if _, ok := b.allow("premium", now.Add(90*time.Second), 20*time.Second); !ok {
+		t.Error("check after the throttled gap not allowed")
+	}
+
+	b.used = b.daily
+	if _, ok := b.allow("premium", now.Add(time.Hour), 20*time.Second); ok {
+		t.Error("allowed a check with the budget spent")
+	}
+	next := time.Date(2026, 3, 2, 23, 59, 0, 0, time.UTC)
+	if _, ok := b.allow("premium", next, 20*time.Second); !ok {
+		t.Error("budget did not reset on a new UTC day")
+	}
+	if b.status(next).Throttled {
+		t.Error("throttled with a full budget and one minute left")
+	}
+}
+
+func TestRequestBudget_Unlimited(t *testing.T) {
+	b := newRequestBudget("public", 0, 2)
+	b.entities = 1
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		res, ok := b.allow("public", now, time.Second)
+		if !ok {
+			t.Fatal("unlimited budget refused a check")
+		}
+		b.record(res, 2, now)
+	}
+	if st := b.status(now); st.Used != 200 || st.Throttled {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestRequestBudget_ConcurrentReserve(t *testing.T) {
+	b := newRequestBudget("premium", 10, 2)
+	b.entities = 5
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	b.roll(now)
+	b.used = 6
+	// Five family series share 4 remaining requests: only two checks fit.
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(entity string) {
+			defer wg.Done()
+			if _, ok := b.allow(entity, now, 20*time.Second); ok {
+				allowed.Add(1)
+			}
+		}(fmt.Sprintf("premium@%d", i))
+	}
+	wg.Wait()
+	if n := allowed.Load(); n != 2 {
+		t.Errorf("allowed %d checks, want 2", n)
+	}
+	if st := b.status(now); st.Used != 10 {
+		t.Errorf("used = %d, want the 2 reservations on top of 6", st.Used)
+	}
+}
+
+func TestNewRunner_SeedsBudgetFromTodaysChecks(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	cfg := &config.Config{RPCProviders: []config.RPCProvider{
+		{Name: "premium", URL: "https://premium.example", DailyRequestBudget: 1000, IPFamilies: []string{config.IPFamilyV6}},
+		{Name: "other", URL: "https://other.example", DailyRequestBudget: 1000},
+	}}
+	if err := config.Validate(cfg); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"premium", "premium", "premium", "premium@ipv6", "premium@ipv6", "other"} {
+		_ = st.InsertCheck(ctx, "rpc", name, true, nil, "")
+	}
+	// Yesterday's checks no longer count.
+	_ = st.InsertCheck(ctx, "rpc", "premium", true, nil, "")
+	if _, err := st.DB().ExecContext(ctx, `UPDATE checks SET created_at = datetime('now', '-1 day') WHERE id = (SELECT MAX(id) FROM checks)`); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRunner(cfg, st, nil)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	perCheck := r.rpcCheckers["premium"].RequestsPerCheck()
+	budgets := r.RequestBudgets()
+	if len(budgets) != 2 || budgets[1].Provider != "premium" || budgets[1].Used != 5*perCheck {
+		t.Fatalf("budgets = %+v, want premium to have used %d", budgets, 5*perCheck)
+	}
+	if budgets[0].Used != perCheck || budgets[1].Remaining != 1000-5*perCheck {
+		t.Errorf("budgets = %+v", budgets)
+	}
+}
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/metrics"
+)
+
+// circuit counts consecutive failures of one entity. Once open, the entity is
+// only probed every CircuitProbeIntervalSecs with a lightweight request until
+// a probe succeeds.
+type circuit struct {
+	failures  int
+	open      bool
+	nextProbe time.Time
+}
+
+// circuitGate reports whether the entity should be checked on this tick and,
+// if so, whether the check must be the lightweight probe.
+func (r *Runner) circuitGate(entityType, name string) (check, probe bool) {
+	r.circuitMu.Lock()
+	defer r.circuitMu.Unlock()
+	c := r.circuits[entityType+"/"+name]
+	if c == nil || !c.open {
+		return true, false
+	}
+	now := r.now()
+	if now.Before(c.nextProbe) {
+		return false, false
+	}
+	c.nextProbe = now.Add(r.probeInterval())
+	return true, true
+}
+
+func (r *Runner) recordCircuit(entityType, name string, success bool) {
+	threshold := r.cfg.Thresholds.CircuitOpenAfterFailures
+	if threshold <= 0 {
+		return
+	}
+	r.circuitMu.Lock()
+	defer r.circuitMu.Unlock()
+	key := entityType + "/" + name
+	c := r.circuits[key]
+	if c == nil {
+		c = &circuit{}
+		r.circuits[key] = c
+	}
+	if success {
+		if c.open {
+			r.log.Info("circuit closed, resuming normal checks", "entity_type", entityType, "name", name)
+			metrics.SetCircuitOpen(entityType, name, false)
+		}
+		*c = circuit{}
+		return
+	}
+	c.failures++
+	if !c.open && c.failures >= threshold {
+		c.open = true
+		c.nextProbe = r.now().Add(r.probeInterval())
+		r.log.Warn("circuit opened, probing at reduced rate", "entity_type", entityType, "name", name,
+			"failures", c.failures, "probe_interval", r.probeInterval())
+		metrics.SetCircuitOpen(entityType, name, true)
+	}
+}
+
+// CircuitOpen reports whether the entity is currently in the open-circuit state.
+func (r *Runner) CircuitOpen(entityType, name string) bool {
+	r.circuitMu.Lock()
+	defer r.circuitMu.Unlock()
+	c := r.circuits[entityType+"/"+name]
+	return c != nil && c.open
+}
+
+func (r *Runner) probeInterval() time.Duration {
+	return time.Duration(r.cfg.Thresholds.CircuitProbeIntervalSecs) * time.Second
+}
@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/config"
+)
+
+func TestCircuit(t *testing.T) {
+	cfg := &config.Config{Thresholds: config.Thresholds{CircuitOpenAfterFailures: 3, CircuitProbeIntervalSecs: 60}}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &Runner{cfg: cfg, log: slog.Default(), circuits: make(map[string]*circuit), now: func() time.Time { return now }}
+
+	for i := 0; i < 2; i++ {
+		r.recordCircuit("rpc", "p", false)
+	}
+	if r.CircuitOpen("rpc", "p") {
+		t.Fatal("circuit open before threshold")
+	}
+	r.recordCircuit("rpc", "p", false)
+	if !r.CircuitOpen("rpc", "p") {
+		t.Fatal("circuit not open at threshold")
+	}
+	if check, _ := r.circuitGate("rpc", "p"); check {
+		t.Error("checked while waiting for next probe")
+	}
+	now = now.Add(61 * time.Second)
+	check, probe := r.circuitGate("rpc", "p")
+	if !check || !probe {
+		t.Errorf("circuitGate = %v, %v; want probe", check, probe)
+	}
+	if check, _ := r.circuitGate("rpc", "p"); check {
+		t.Error("second probe in the same interval")
+	}
+	r.recordCircuit("rpc", "p", true)
+	if r.CircuitOpen("rpc", "p") {
+		t.Fatal("circuit still open after successful probe")
+	}
+	if check, probe := r.circuitGate("rpc", "p"); !check || probe {
+		t.Errorf("circuitGate = %v, %v; want full check", check, probe)
+	}
+}
+
+func TestCircuit_Disabled(t *testing.T) {
+	r := &Runner{cfg: &config.Config{}, log: slog.Default(), circuits: make(map[string]*circuit), now: time.Now}
+	for i := 0; i < 100; i++ {
+		r.recordCircuit("dapp", "d", false)
+	}
+	if r.CircuitOpen("dapp", "d") {
+		t.Error("circuit opened with circuit_open_after_failures = 0")
+	}
+}
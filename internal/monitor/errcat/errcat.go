@@ -0,0 +1,84 @@
+package errcat
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+const (
+	Timeout           = "timeout"
+	DNS               = "dns"
+	TLS               = "tls"
+	ConnRefused       = "conn_refused"
+	ConnReset         = "conn_reset"
+	HTTPStatus        = "http_status"
+	HTTP4xx           = "http_4xx"
+	HTTP5xx           = "http_5xx"
+	BodyRead          = "body_read"
+	JSONDecode        = "json_decode"
+	UnexpectedPayload = "unexpected_payload"
+)
+
+// Categorize maps a transport error from an HTTP or TCP dial to a category.
+// Errors that match nothing more specific are reported as UnexpectedPayload.
+func Categorize(err error) string {
+	if err == nil {
+		return ""
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return DNS
+	}
+	var addrErr *net.AddrError
+	if errors.As(err, &addrErr) {
+		return DNS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ConnRefused
+	}
+	if errors.Is(err, syscall.ECONNRESET) {
+		return ConnReset
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Timeout
+	}
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authErr x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &authErr) ||
+		errors.As(err, &hostErr) || errors.As(err, &invalidErr) {
+		return TLS
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline"):
+		return Timeout
+	case strings.Contains(msg, "connection refused"):
+		return ConnRefused
+	case strings.Contains(msg, "connection reset"):
+		return ConnReset
+	case strings.Contains(msg, "tls") || strings.Contains(msg, "TLS") || strings.Contains(msg, "certificate"):
+		return TLS
+	}
+	return UnexpectedPayload
+}
+
+func FromStatus(code int) string {
+	switch {
+	case code >= 200 && code < 400:
+		return ""
+	case code >= 400 && code < 500:
+		return HTTP4xx
+	case code >= 500 && code < 600:
+		return HTTP5xx
+	default:
+		return HTTPStatus
+	}
+}
@@ -0,0 +1,54 @@
+package errcat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCategorize(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	_, refused := net.DialTimeout("tcp", addr, time.Second)
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+	_, untrusted := http.Get(tlsServer.URL)
+
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"dns", &net.DNSError{Err: "no such host", Name: "nope.invalid"}, DNS},
+		{"refused", refused, ConnRefused},
+		{"deadline", fmt.Errorf("get: %w", context.DeadlineExceeded), Timeout},
+		{"tls", untrusted, TLS},
+		{"reset", errors.New("read tcp: connection reset by peer"), ConnReset},
+		{"other", errors.New("boom"), UnexpectedPayload},
+	}
+	for _, tc := range cases {
+		if got := Categorize(tc.err); got != tc.want {
+			t.Errorf("%s: Categorize(%v) = %q, want %q", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	cases := map[int]string{200: "", 301: "", 404: HTTP4xx, 429: HTTP4xx, 503: HTTP5xx, 101: HTTPStatus}
+	for code, want := range cases {
+		if got := FromStatus(code); got != want {
+			t.Errorf("FromStatus(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
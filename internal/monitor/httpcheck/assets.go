@@ -0,0 +1,260 @@
+package httpcheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/monitor/errcat"
+)
+
+const (
+	ErrorCategoryAssetLoad = "asset_load"
+	ErrorCategoryAssetHash = "asset_hash"
+	ErrorCategoryAssetHost = "asset_host"
+)
+
+const (
+	maxPageBytes  = 4 << 20
+	maxAssetBytes = 16 << 20
+)
+
+type AssetOptions struct {
+	// AllowedHosts limits where scripts and stylesheets may be served from.
+	// The page's own host is always allowed; "*.example.com" matches subdomains.
+	// Empty means any host.
+	AllowedHosts []string
+	// Pinned maps an asset URL to an SRI digest such as "sha384-<base64>".
+	Pinned    map[string]string
+	MaxAssets int
+}
+
+type AssetResult struct {
+	URL           string
+	Kind          string
+	Status        int
+	LatencyMs     int64
+	Success       bool
+	ErrorCategory string
+}
+
+var (
+	tagRe  = regexp.MustCompile(`(?is)<(script|link)\b([^>]*)>`)
+	attrRe = regexp.MustCompile(`(?is)([a-z][a-z0-9_:-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+type assetRef struct {
+	url       string
+	kind      string
+	integrity string
+}
+
+func findAssets(page []byte, base *url.URL) []assetRef {
+	var out []assetRef
+	seen := make(map[string]bool)
+	for _, m := range tagRe.FindAllSubmatch(page, -1) {
+		tag := strings.ToLower(string(m[1]))
+		attrs := parseAttrs(string(m[2]))
+		var ref, kind string
+		switch tag {
+		case "script":
+			ref, kind = attrs["src"], "script"
+		case "link":
+			if !hasToken(attrs["rel"], "stylesheet") {
+				continue
+			}
+			ref, kind = attrs["href"], "stylesheet"
+		}
+		if ref == "" {
+			continue
+		}
+		u, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		abs := u.String()
+		if seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		out = append(out, assetRef{url: abs, kind: kind, integrity: attrs["integrity"]})
+	}
+	return out
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRe.FindAllStringSubmatch(s, -1) {
+		v := m[2]
+		if v == "" {
+			v = m[3]
+		}
+		if v == "" {
+			v = m[4]
+		}
+		attrs[strings.ToLower(m[1])] = v
+	}
+	return attrs
+}
+
+func hasToken(list, token string) bool {
+	for _, f := range strings.Fields(strings.ToLower(list)) {
+		if f == token {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) checkAssets(ctx context.Context, pageURL *url.URL, page []byte) []AssetResult {
+	refs := findAssets(page, pageURL)
+	if c.Assets.MaxAssets > 0 && len(refs) > c.Assets.MaxAssets {
+		refs = refs[:c.Assets.MaxAssets]
+	}
+	out := make([]AssetResult, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, c.checkAsset(ctx, pageURL.Hostname(), ref))
+	}
+	return out
+}
+
+func (c *Checker) checkAsset(ctx context.Context, pageHost string, ref assetRef) AssetResult {
+	res := AssetResult{URL: ref.url, Kind: ref.kind}
+	u, _ := url.Parse(ref.url)
+	if !hostAllowed(u.Hostname(), pageHost, c.Assets.AllowedHosts) {
+		res.ErrorCategory = ErrorCategoryAssetHost
+		return res
+	}
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.url, nil)
+	if err != nil {
+		res.ErrorCategory = ErrorCategoryAssetLoad
+		return res
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		res.LatencyMs = time.Since(start).Milliseconds()
+		res.ErrorCategory = ErrorCategoryAssetLoad
+		return res
+	}
+	defer func() { _ = resp.Body.Close() }()
+	res.Status = resp.StatusCode
+	digests := expectedDigests(c.Assets.Pinned[ref.url], ref.integrity)
+	hashers := make([]hash.Hash, len(digests))
+	writers := []io.Writer{io.Discard}
+	for i, d := range digests {
+		hashers[i] = d.newHash()
+		writers = append(writers, hashers[i])
+	}
+	_, err = io.Copy(io.MultiWriter(writers...), io.LimitReader(resp.Body, maxAssetBytes))
+	res.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil || errcat.FromStatus(resp.StatusCode) != "" {
+		res.ErrorCategory = ErrorCategoryAssetLoad
+		return res
+	}
+	if len(digests) > 0 {
+		matched := false
+		for i, d := range digests {
+			if base64.StdEncoding.EncodeToString(hashers[i].Sum(nil)) == d.b64 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			res.ErrorCategory = ErrorCategoryAssetHash
+			return res
+		}
+	}
+	res.Success = true
+	return res
+}
+
+func hostAllowed(host, pageHost string, allowed []string) bool {
+	if len(allowed) == 0 || strings.EqualFold(host, pageHost) {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if strings.HasPrefix(a, "*.") {
+			if strings.HasSuffix(host, a[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == a {
+			return true
+		}
+	}
+	return false
+}
+
+type digest struct {
+	algo string
+	b64  string
+}
+
+func (d digest) newHash() hash.Hash {
+	switch d.algo {
+	case "sha512":
+		return sha512.New()
+	case "sha384":
+		return sha512.New384()
+	default:
+		return sha256.New()
+	}
+}
+
+// expectedDigests parses SRI values ("sha384-<base64>", space separated); the
+// asset passes if any one matches. A pinned value overrides the page's own
+// integrity attribute.
+func expectedDigests(pinned, integrity string) []digest {
+	src := pinned
+	if src == "" {
+		src = integrity
+	}
+	var out []digest
+	for _, f := range strings.Fields(src) {
+		algo, b64, ok := strings.Cut(f, "-")
+		if !ok {
+			continue
+		}
+		if i := strings.IndexByte(b64, '?'); i >= 0 {
+			b64 = b64[:i]
+		}
+		switch algo {
+		case "sha256", "sha384", "sha512":
+			out = append(out, digest{algo: algo, b64: b64})
+		}
+	}
+	return out
+}
+
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n <= 0 {
+		return len(p), nil
+	}
+	chunk := p
+	if int64(len(chunk)) > l.n {
+		chunk = chunk[:l.n]
+	}
+	n, err := l.w.Write(chunk)
+	l.n -= int64(n)
+	if err != nil {
+		return n, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,124 @@
+package httpcheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const appJS = "console.log('hello');"
+
+func assetServer(html string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(html))
+		case "/static/app.js":
+			_, _ = w.Write([]byte(appJS))
+		case "/static/app.css":
+			_, _ = w.Write([]byte("body{}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func sri(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestFindAssets(t *testing.T) {
+	base, _ := url.Parse("https://app.example.com/swap/")
+	page := []byte(`<html><head>
+<link rel="stylesheet" href="/static/app.css">
+<link rel="icon" href="/favicon.ico">
+<script src='main.js' integrity="sha256-abc"></script>
+<script>inline()</script>
+<SCRIPT SRC=https://cdn.example.net/lib.js></SCRIPT>
+<script src="/static/app.css"></script>
+</head></html>`)
+	refs := findAssets(page, base)
+	want := []string{
+		"https://app.example.com/static/app.css",
+		"https://app.example.com/swap/main.js",
+		"https://cdn.example.net/lib.js",
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("refs = %+v", refs)
+	}
+	for i, w := range want {
+		if refs[i].url != w {
+			t.Errorf("refs[%d] = %q, want %q", i, refs[i].url, w)
+		}
+	}
+	if refs[1].integrity != "sha256-abc" {
+		t.Errorf("integrity = %q", refs[1].integrity)
+	}
+}
+
+func TestChecker_Check_Assets(t *testing.T) {
+	server := assetServer(`<link rel="stylesheet" href="/static/app.css"><script src="/static/app.js"></script>`)
+	defer server.Close()
+	checker := NewChecker(server.URL+"/", 0)
+	checker.Assets = &AssetOptions{Pinned: map[string]string{server.URL + "/static/app.js": sri(appJS)}}
+	res := checker.Check(context.Background())
+	if !res.Success {
+		t.Fatalf("expected success: %+v", res)
+	}
+	if len(res.Assets) != 2 {
+		t.Errorf("assets = %+v", res.Assets)
+	}
+
+	checker.Assets.Pinned[server.URL+"/static/app.js"] = sri("tampered")
+	res = checker.Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryAssetHash {
+		t.Errorf("expected asset_hash: %+v", res)
+	}
+}
+
+func TestChecker_Check_AssetMissing(t *testing.T) {
+	server := assetServer(`<script src="/static/missing.js"></script>`)
+	defer server.Close()
+	checker := NewChecker(server.URL+"/", 0)
+	checker.Assets = &AssetOptions{}
+	res := checker.Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryAssetLoad {
+		t.Errorf("expected asset_load: %+v", res)
+	}
+	if res.Status != http.StatusOK {
+		t.Errorf("page status = %d", res.Status)
+	}
+}
+
+func TestChecker_Check_AssetHost(t *testing.T) {
+	server := assetServer(`<script src="/static/app.js"></script><script src="https://evil.example.org/drain.js"></script>`)
+	defer server.Close()
+	checker := NewChecker(server.URL+"/", 0)
+	checker.Assets = &AssetOptions{AllowedHosts: []string{"*.jsdelivr.net"}}
+	res := checker.Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryAssetHost {
+		t.Errorf("expected asset_host: %+v", res)
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	allowed := []string{"cdn.example.com", "*.jsdelivr.net"}
+	cases := map[string]bool{
+		"app.example.com":      true,
+		"cdn.example.com":      true,
+		"fastly.jsdelivr.net":  true,
+		"jsdelivr.net.evil.io": false,
+		"evil.example.org":     false,
+	}
+	for host, want := range cases {
+		if got := hostAllowed(host, "app.example.com", allowed); got != want {
+			t.Errorf("hostAllowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -1,21 +1,47 @@
 package httpcheck
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"net"
 	"net/http"
 	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/monitor/errcat"
+)
+
+const (
+	ErrorCategoryTimeout           = errcat.Timeout
+	ErrorCategoryDNS               = errcat.DNS
+	ErrorCategoryTLS               = errcat.TLS
+	ErrorCategoryConnRefused       = errcat.ConnRefused
+	ErrorCategoryConnReset         = errcat.ConnReset
+	ErrorCategoryHTTPStatus        = errcat.HTTPStatus
+	ErrorCategoryHTTP4xx           = errcat.HTTP4xx
+	ErrorCategoryHTTP5xx           = errcat.HTTP5xx
+	ErrorCategoryBodyRead          = errcat.BodyRead
+	ErrorCategoryUnexpectedPayload = errcat.UnexpectedPayload
 )
 
 type Result struct {
-	Success   bool
-	LatencyMs int64
-	Status    int
+	Success       bool
+	LatencyMs     int64
+	Status        int
+	ErrorCategory string
+	Assets        []AssetResult
+	Steps         []StepResult
 }
 
 type Checker struct {
 	URL        string
 	HTTPClient *http.Client
+	// Cold forces a fresh connection per check, like a first page load.
+	Cold bool
+	// Assets, when set, makes Check follow the page's scripts and stylesheets.
+	Assets *AssetOptions
+	// Journey, when set, replaces the single GET of URL with these steps.
+	Journey []Step
 }
 
 func NewChecker(url string, timeout time.Duration) *Checker {
@@ -26,26 +52,84 @@ func NewChecker(url string, timeout time.Duration) *Checker {
 		URL: url,
 		HTTPClient: &http.Client{
 			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:       http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
+			},
 		},
 	}
 }
 
+// Probe is the lightweight check used while the endpoint's circuit is open:
+// the page without its assets, or only the first step of a journey.
+func (c *Checker) Probe(ctx context.Context) Result {
+	light := *c
+	light.Cold = false
+	light.Assets = nil
+	if len(light.Journey) > 1 {
+		light.Journey = light.Journey[:1]
+	}
+	return light.Check(ctx)
+}
+
 func (c *Checker) Check(ctx context.Context) Result {
+	if c.Cold {
+		c.HTTPClient.CloseIdleConnections()
+	}
+	if len(c.Journey) > 0 {
+		return c.runJourney(ctx)
+	}
 	start := time.Now()
 	res := Result{}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
 	if err != nil {
+		res.ErrorCategory = ErrorCategoryUnexpectedPayload
 		return res
 	}
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		res.LatencyMs = time.Since(start).Milliseconds()
+		res.ErrorCategory = errcat.Categorize(err)
 		return res
 	}
 	defer func() { _ = resp.Body.Close() }()
-	_, _ = io.Copy(io.Discard, resp.Body)
-	res.LatencyMs = time.Since(start).Milliseconds()
 	res.Status = resp.StatusCode
-	res.Success = resp.StatusCode >= 200 && resp.StatusCode <= 399
+	var page bytes.Buffer
+	sink := io.Writer(io.Discard)
+	if c.Assets != nil {
+		sink = &limitedWriter{w: &page, n: maxPageBytes}
+	}
+	if _, err := io.Copy(sink, resp.Body); err != nil {
+		res.LatencyMs = time.Since(start).Milliseconds()
+		if errCat := errcat.Categorize(err); errCat == ErrorCategoryTimeout || errCat == ErrorCategoryConnReset {
+			res.ErrorCategory = errCat
+		} else {
+			res.ErrorCategory = ErrorCategoryBodyRead
+		}
+		return res
+	}
+	res.LatencyMs = time.Since(start).Milliseconds()
+	res.ErrorCategory = errcat.FromStatus(resp.StatusCode)
+	res.Success = res.ErrorCategory == ""
+	if res.Success && c.Assets != nil {
+		res.Assets = c.checkAssets(ctx, resp.Request.URL, page.Bytes())
+		for _, a := range res.Assets {
+			if !a.Success {
+				res.Success = false
+				res.ErrorCategory = a.ErrorCategory
+				break
+			}
+		}
+	}
 	return res
 }
+
+func (r *Result) ErrorSummary() string {
+	if r.Success {
+		return ""
+	}
+	if r.ErrorCategory != "" {
+		return r.ErrorCategory
+	}
+	return "unknown"
+}
@@ -2,8 +2,10 @@ package httpcheck
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 )
 
@@ -50,4 +52,73 @@ func TestChecker_Check_4xx(t *testing.T) {
 	if res.Status != 404 {
 		t.Errorf("status = %d", res.Status)
 	}
+	if res.ErrorCategory != ErrorCategoryHTTP4xx {
+		t.Errorf("error_category = %q", res.ErrorCategory)
+	}
+}
+
+func TestChecker_Check_5xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	res := NewChecker(server.URL, 0).Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryHTTP5xx {
+		t.Errorf("expected http_5xx: %+v", res)
+	}
+}
+
+func TestChecker_Check_ConnRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+	res := NewChecker(url, 0).Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryConnRefused {
+		t.Errorf("expected conn_refused: %+v", res)
+	}
+}
+
+func TestChecker_Check_BodyRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1000")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("partial"))
+	}))
+	defer server.Close()
+	res := NewChecker(server.URL, 0).Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryBodyRead {
+		t.Errorf("expected body_read: %+v", res)
+	}
+}
+
+func TestChecker_Check_ConnectionReuse(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	warm := NewChecker(server.URL, 0)
+	for i := 0; i < 3; i++ {
+		_ = warm.Check(context.Background())
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("warm checker opened %d connections, want 1", n)
+	}
+
+	conns.Store(0)
+	cold := NewChecker(server.URL, 0)
+	cold.Cold = true
+	for i := 0; i < 3; i++ {
+		_ = cold.Check(context.Background())
+	}
+	if n := conns.Load(); n != 3 {
+		t.Errorf("cold checker opened %d connections, want 3", n)
+	}
 }
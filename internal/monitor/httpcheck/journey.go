@@ -0,0 +1,237 @@
+package httpcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/monitor/errcat"
+)
+
+const (
+	ErrorCategoryJourneyExtract = "journey_extract"
+	ErrorCategoryJourneyAssert  = "journey_assert"
+)
+
+type Step struct {
+	Name    string
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+	// Extract maps a variable name to "$.json.path" or "header:Name".
+	Extract map[string]string
+	Assert  StepAssert
+}
+
+type StepAssert struct {
+	// Status is the expected status code; 0 accepts any 2xx or 3xx.
+	Status       int
+	BodyContains string
+	// JSON maps "$.json.path" to the expected value, compared as a string.
+	JSON         map[string]string
+	MaxLatencyMs int64
+}
+
+type StepResult struct {
+	Name          string
+	Status        int
+	LatencyMs     int64
+	Success       bool
+	ErrorCategory string
+	Detail        string
+}
+
+var varRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+func expand(s string, vars map[string]string) string {
+	return varRe.ReplaceAllStringFunc(s, func(m string) string {
+		name := varRe.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+func (c *Checker) runJourney(ctx context.Context) Result {
+	start := time.Now()
+	res := Result{}
+	vars := make(map[string]string)
+	for _, step := range c.Journey {
+		sr := c.runStep(ctx, step, vars)
+		res.Steps = append(res.Steps, sr)
+		res.Status = sr.Status
+		if !sr.Success {
+			res.LatencyMs = time.Since(start).Milliseconds()
+			res.ErrorCategory = sr.ErrorCategory
+			return res
+		}
+	}
+	res.LatencyMs = time.Since(start).Milliseconds()
+	res.Success = true
+	return res
+}
+
+func (c *Checker) runStep(ctx context.Context, step Step, vars map[string]string) StepResult {
+	sr := StepResult{Name: step.Name}
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if step.Body != "" {
+		body = strings.NewReader(expand(step.Body, vars))
+	}
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, method, expand(step.URL, vars), body)
+	if err != nil {
+		sr.ErrorCategory = ErrorCategoryUnexpectedPayload
+		sr.Detail = err.Error()
+		return sr
+	}
+	for k, v := range step.Headers {
+		req.Header.Set(k, expand(v, vars))
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		sr.LatencyMs = time.Since(start).Milliseconds()
+		sr.ErrorCategory = errcat.Categorize(err)
+		return sr
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	_ = resp.Body.Close()
+	sr.LatencyMs = time.Since(start).Milliseconds()
+	sr.Status = resp.StatusCode
+	if err != nil {
+		sr.ErrorCategory = ErrorCategoryBodyRead
+		return sr
+	}
+
+	if step.Assert.Status != 0 {
+		if resp.StatusCode != step.Assert.Status {
+			sr.ErrorCategory = errcat.FromStatus(resp.StatusCode)
+			if sr.ErrorCategory == "" {
+				sr.ErrorCategory = ErrorCategoryJourneyAssert
+			}
+			sr.Detail = fmt.Sprintf("status %d, want %d", resp.StatusCode, step.Assert.Status)
+			return sr
+		}
+	} else if errCat := errcat.FromStatus(resp.StatusCode); errCat != "" {
+		sr.ErrorCategory = errCat
+		return sr
+	}
+
+	var doc interface{}
+	docParsed := false
+	parseDoc := func() bool {
+		if !docParsed {
+			docParsed = true
+			if err := json.Unmarshal(data, &doc); err != nil {
+				doc = nil
+			}
+		}
+		return doc != nil
+	}
+
+	if step.Assert.BodyContains != "" && !strings.Contains(string(data), expand(step.Assert.BodyContains, vars)) {
+		sr.ErrorCategory = ErrorCategoryJourneyAssert
+		sr.Detail = "body does not contain " + strconv.Quote(step.Assert.BodyContains)
+		return sr
+	}
+	for path, want := range step.Assert.JSON {
+		if !parseDoc() {
+			sr.ErrorCategory = errcat.JSONDecode
+			return sr
+		}
+		got, ok := lookupJSON(doc, path)
+		if !ok || got != expand(want, vars) {
+			sr.ErrorCategory = ErrorCategoryJourneyAssert
+			sr.Detail = fmt.Sprintf("%s = %q, want %q", path, got, want)
+			return sr
+		}
+	}
+	if step.Assert.MaxLatencyMs > 0 && sr.LatencyMs > step.Assert.MaxLatencyMs {
+		sr.ErrorCategory = ErrorCategoryJourneyAssert
+		sr.Detail = fmt.Sprintf("latency %d ms, want <= %d ms", sr.LatencyMs, step.Assert.MaxLatencyMs)
+		return sr
+	}
+
+	for name, src := range step.Extract {
+		var v string
+		var ok bool
+		if header, isHeader := strings.CutPrefix(src, "header:"); isHeader {
+			v = resp.Header.Get(strings.TrimSpace(header))
+			ok = v != ""
+		} else if parseDoc() {
+			v, ok = lookupJSON(doc, src)
+		}
+		if !ok {
+			sr.ErrorCategory = ErrorCategoryJourneyExtract
+			sr.Detail = "could not extract " + name + " from " + src
+			return sr
+		}
+		vars[name] = v
+	}
+	sr.Success = true
+	return sr
+}
+
+// lookupJSON resolves a dotted path such as "$.data.routes[0].id" and returns
+// the value as a string; objects and arrays are returned as compact JSON.
+func lookupJSON(doc interface{}, path string) (string, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	cur := doc
+	for path != "" {
+		var key string
+		if strings.HasPrefix(path, "[") {
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return "", false
+			}
+			idx, err := strconv.Atoi(path[1:end])
+			arr, ok := cur.([]interface{})
+			if err != nil || !ok || idx < 0 || idx >= len(arr) {
+				return "", false
+			}
+			cur = arr[idx]
+			path = strings.TrimPrefix(path[end+1:], ".")
+			continue
+		}
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			key, path = path, ""
+		} else {
+			key, path = path[:end], strings.TrimPrefix(path[end:], ".")
+		}
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if cur, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+	switch v := cur.(type) {
+	case nil:
+		return "null", true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+}
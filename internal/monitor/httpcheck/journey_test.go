@@ -0,0 +1,113 @@
+package httpcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func journeyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/quote":
+			w.Header().Set("X-Session", "s-42")
+			_, _ = w.Write([]byte(`{"data":{"routes":[{"id":"q-1","ok":true}]}}`))
+		case "/build":
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get("X-Session") != "s-42" || string(body) != `{"quote":"q-1"}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"tx":"built"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestChecker_Journey(t *testing.T) {
+	srv := journeyServer()
+	defer srv.Close()
+	c := NewChecker(srv.URL, 2*time.Second)
+	c.Journey = []Step{
+		{
+			Name:    "quote",
+			URL:     srv.URL + "/quote",
+			Extract: map[string]string{"quote_id": "$.data.routes[0].id", "session": "header:X-Session"},
+			Assert:  StepAssert{Status: 200, JSON: map[string]string{"$.data.routes[0].ok": "true"}},
+		},
+		{
+			Name:    "build",
+			Method:  http.MethodPost,
+			URL:     srv.URL + "/build",
+			Headers: map[string]string{"X-Session": "{{session}}"},
+			Body:    `{"quote":"{{quote_id}}"}`,
+			Assert:  StepAssert{BodyContains: "built"},
+		},
+	}
+	res := c.Check(context.Background())
+	if !res.Success {
+		t.Fatalf("Success = false, category %q, steps %+v", res.ErrorCategory, res.Steps)
+	}
+	if len(res.Steps) != 2 || !res.Steps[1].Success {
+		t.Errorf("steps = %+v", res.Steps)
+	}
+}
+
+func TestChecker_Journey_FailingStep(t *testing.T) {
+	srv := journeyServer()
+	defer srv.Close()
+	tests := []struct {
+		name string
+		step Step
+		want string
+	}{
+		{"status", Step{URL: srv.URL + "/missing"}, ErrorCategoryHTTP4xx},
+		{"json", Step{URL: srv.URL + "/quote", Assert: StepAssert{JSON: map[string]string{"$.data.routes[0].id": "q-2"}}}, ErrorCategoryJourneyAssert},
+		{"extract", Step{URL: srv.URL + "/quote", Extract: map[string]string{"x": "$.data.nope"}}, ErrorCategoryJourneyExtract},
+		{"body", Step{URL: srv.URL + "/quote", Assert: StepAssert{BodyContains: "nope"}}, ErrorCategoryJourneyAssert},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(srv.URL, 2*time.Second)
+			c.Journey = []Step{tt.step, {Name: "never", URL: srv.URL + "/quote"}}
+			res := c.Check(context.Background())
+			if res.Success {
+				t.Fatal("Success = true")
+			}
+			if res.ErrorCategory != tt.want {
+				t.Errorf("ErrorCategory = %q, want %q", res.ErrorCategory, tt.want)
+			}
+			if len(res.Steps) != 1 {
+				t.Errorf("ran %d steps, want 1", len(res.Steps))
+			}
+		})
+	}
+}
+
+func TestLookupJSON(t *testing.T) {
+	var doc interface{} = map[string]interface{}{
+		"a": map[string]interface{}{"b": []interface{}{1.5, "x", nil}},
+	}
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"$.a.b[0]", "1.5", true},
+		{"$.a.b[1]", "x", true},
+		{"$.a.b[2]", "null", true},
+		{"$.a.b[3]", "", false},
+		{"$.a.c", "", false},
+		{"$.a.b", `[1.5,"x",null]`, true},
+	}
+	for _, tt := range tests {
+		got, ok := lookupJSON(doc, tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("lookupJSON(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
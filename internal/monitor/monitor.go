@@ -2,20 +2,28 @@ package monitor
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorusys/aptos-guardian/internal/config"
 	"github.com/gorusys/aptos-guardian/internal/metrics"
 	"github.com/gorusys/aptos-guardian/internal/monitor/httpcheck"
+	"github.com/gorusys/aptos-guardian/internal/monitor/promcheck"
 	"github.com/gorusys/aptos-guardian/internal/monitor/rpc"
+	"github.com/gorusys/aptos-guardian/internal/monitor/tcpcheck"
+	"github.com/gorusys/aptos-guardian/internal/monitor/transport"
 	"github.com/gorusys/aptos-guardian/internal/store"
 )
 
 type IncidentProcessor interface {
-	ProcessRPCResult(ctx context.Context, name, url string, success bool, latencyMs int64) (opened, closed bool, err error)
+	ProcessRPCResult(ctx context.Context, name, url string, success bool) (opened, closed bool, err error)
 	ProcessDappResult(ctx context.Context, name, url string, success bool) (opened, closed bool, err error)
+	ProcessTCPResult(ctx context.Context, name, address string, success bool) (opened, closed bool, err error)
+	ProcessNodeMetricsResult(ctx context.Context, name, url string, success bool, severity, summary string) (opened, closed bool, err error)
 }
 
 type Runner struct {
@@ -23,13 +31,145 @@ type Runner struct {
 	store  *store.Store
 	engine IncidentProcessor
 	log    *slog.Logger
+
+	// Targets are the configured endpoints plus one copy per forced IP family.
+	rpcTargets  []config.RPCProvider
+	dappTargets []config.DappEndpoint
+	tcpTargets  []config.TCPTarget
+
+	// Checkers live for the life of the process so warm endpoints reuse connections.
+	rpcCheckers  map[string]*rpc.Checker
+	dappCheckers map[string]*httpcheck.Checker
+	tcpCheckers  map[string]*tcpcheck.Checker
+	nodeCheckers map[string]*promcheck.Checker
+
+	// rpcBudgets maps each RPC entity to its provider's daily request budget.
+	rpcBudgets map[string]*requestBudget
+
+	circuitMu sync.Mutex
+	circuits  map[string]*circuit
+	now       func() time.Time
 }
 
-func NewRunner(cfg *config.Config, st *store.Store, log *slog.Logger) *Runner {
+func NewRunner(cfg *config.Config, st *store.Store, log *slog.Logger) (*Runner, error) {
 	if log == nil {
 		log = slog.Default()
 	}
-	return &Runner{cfg: cfg, store: st, log: log}
+	r := &Runner{
+		cfg:          cfg,
+		store:        st,
+		log:          log,
+		rpcCheckers:  make(map[string]*rpc.Checker, len(cfg.RPCProviders)),
+		dappCheckers: make(map[string]*httpcheck.Checker, len(cfg.Dapps)),
+		tcpCheckers:  make(map[string]*tcpcheck.Checker, len(cfg.TCPTargets)),
+		nodeCheckers: make(map[string]*promcheck.Checker, len(cfg.NodeMetrics)),
+		rpcBudgets:   make(map[string]*requestBudget, len(cfg.RPCProviders)),
+		circuits:     make(map[string]*circuit),
+		now:          time.Now,
+	}
+	for _, p := range cfg.RPCProviders {
+		var budget *requestBudget
+		for _, fam := range families(p.IPFamilies) {
+			target := p
+			target.Name = config.FamilyEntityName(p.Name, fam)
+			checker := rpc.NewChecker(p.URL, p.Timeout.Duration())
+			checker.HealthyDurationSecs = p.HealthyDurationSecs
+			checker.Cold = p.ConnectionMode == config.ConnectionModeCold
+			tr, err := newTransport(p.TransportConfig, fam)
+			if err != nil {
+				return nil, fmt.Errorf("rpc provider %s: %w", p.Name, err)
+			}
+			checker.HTTPClient.Transport = tr
+			if budget == nil {
+				budget = newRequestBudget(p.Name, p.DailyRequestBudget, checker.RequestsPerCheck())
+			}
+			budget.entities++
+			r.rpcBudgets[target.Name] = budget
+			r.rpcCheckers[target.Name] = checker
+			r.rpcTargets = append(r.rpcTargets, target)
+		}
+	}
+	if err := r.seedBudgets(context.Background()); err != nil {
+		return nil, fmt.Errorf("seed request budgets: %w", err)
+	}
+	for _, d := range cfg.Dapps {
+		for _, fam := range families(d.IPFamilies) {
+			target := d
+			target.Name = config.FamilyEntityName(d.Name, fam)
+			checker := httpcheck.NewChecker(d.URL, d.Timeout.Duration())
+			checker.Cold = d.ConnectionMode == config.ConnectionModeCold
+			if a := d.AssetChecks; a != nil {
+				checker.Assets = &httpcheck.AssetOptions{AllowedHosts: a.AllowedHosts, Pinned: a.Pinned, MaxAssets: a.MaxAssets}
+			}
+			for _, st := range d.Journey {
+				checker.Journey = append(checker.Journey, httpcheck.Step{
+					Name: st.Name, Method: st.Method, URL: st.URL, Headers: st.Headers, Body: st.Body, Extract: st.Extract,
+					Assert: httpcheck.StepAssert{
+						Status: st.Assert.Status, BodyContains: st.Assert.BodyContains, JSON: st.Assert.JSON, MaxLatencyMs: st.Assert.MaxLatencyMs,
+					},
+				})
+			}
+			tr, err := newTransport(d.TransportConfig, fam)
+			if err != nil {
+				return nil, fmt.Errorf("dapp %s: %w", d.Name, err)
+			}
+			checker.HTTPClient.Transport = tr
+			r.dappCheckers[target.Name] = checker
+			r.dappTargets = append(r.dappTargets, target)
+		}
+	}
+	for _, t := range cfg.TCPTargets {
+		for _, fam := range families(t.IPFamilies) {
+			target := t
+			target.Name = config.FamilyEntityName(t.Name, fam)
+			checker := tcpcheck.NewChecker(t.Address, t.Timeout.Duration())
+			checker.Network = dialNetwork(fam)
+			checker.TLS = t.TLS
+			checker.ServerName = t.ServerName
+			checker.ReadBanner = t.ReadBanner
+			checker.ExpectBanner = t.ExpectBanner
+			r.tcpCheckers[target.Name] = checker
+			r.tcpTargets = append(r.tcpTargets, target)
+		}
+	}
+	for _, n := range cfg.NodeMetrics {
+		rules := make([]promcheck.Rule, 0, len(n.Rules))
+		for _, rule := range n.Rules {
+			rules = append(rules, promcheck.Rule{
+				Name: rule.Name, Metric: rule.Metric, Labels: rule.Labels, Aggregate: rule.Aggregate,
+				Op: rule.Op, Value: rule.Value, Severity: rule.Severity, StaleAfter: rule.StaleAfter,
+			})
+		}
+		r.nodeCheckers[n.Name] = promcheck.NewChecker(n.URL, n.Timeout.Duration(), rules)
+	}
+	return r, nil
+}
+
+// families returns "" (let Go pick) followed by each forced family.
+func families(configured []string) []string {
+	return append([]string{""}, configured...)
+}
+
+func dialNetwork(family string) string {
+	switch family {
+	case config.IPFamilyV4:
+		return "tcp4"
+	case config.IPFamilyV6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+func newTransport(tc config.TransportConfig, family string) (*http.Transport, error) {
+	return transport.New(transport.Options{
+		ProxyURL:           tc.ProxyURL,
+		CAFile:             tc.CAFile,
+		ClientCertFile:     tc.ClientCertFile,
+		ClientKeyFile:      tc.ClientKeyFile,
+		InsecureSkipVerify: tc.InsecureSkipVerify,
+		Network:            dialNetwork(family),
+	})
 }
 
 func (r *Runner) SetIncidentEngine(engine IncidentProcessor) {
@@ -52,7 +192,7 @@ func (r *Runner) Run(ctx context.Context) {
 
 func (r *Runner) runOnce(ctx context.Context) {
 	var wg sync.WaitGroup
-	for _, p := range r.cfg.RPCProviders {
+	for _, p := range r.rpcTargets {
 		p := p
 		wg.Add(1)
 		go func() {
@@ -60,7 +200,7 @@ func (r *Runner) runOnce(ctx context.Context) {
 			r.checkRPC(ctx, &p)
 		}()
 	}
-	for _, d := range r.cfg.Dapps {
+	for _, d := range r.dappTargets {
 		d := d
 		wg.Add(1)
 		go func() {
@@ -68,13 +208,46 @@ func (r *Runner) runOnce(ctx context.Context) {
 			r.checkDapp(ctx, &d)
 		}()
 	}
+	for _, t := range r.tcpTargets {
+		t := t
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.checkTCP(ctx, &t)
+		}()
+	}
+	for _, n := range r.cfg.NodeMetrics {
+		n := n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.checkNodeMetrics(ctx, &n)
+		}()
+	}
 	wg.Wait()
 }
 
 func (r *Runner) checkRPC(ctx context.Context, p *config.RPCProvider) {
+	check, probe := r.circuitGate("rpc", p.Name)
+	if !check {
+		return
+	}
+	budget := r.rpcBudgets[p.Name]
+	reserved, ok := budget.allow(p.Name, r.now(), r.cfg.Interval)
+	if !ok {
+		r.reportBudget(budget)
+		return
+	}
 	_, _ = r.store.EnsureProvider(ctx, p.Name, p.URL)
-	checker := rpc.NewChecker(p.URL, p.Timeout.Duration())
-	res := checker.Check(ctx)
+	var res rpc.Result
+	if probe {
+		res = r.rpcCheckers[p.Name].Probe(ctx)
+	} else {
+		res = r.rpcCheckers[p.Name].Check(ctx)
+	}
+	budget.record(reserved, res.Requests, r.now())
+	metrics.AddProviderRequests(budget.provider, res.Requests)
+	r.reportBudget(budget)
 	latPtr := (*int64)(nil)
 	if res.Success {
 		latPtr = &res.LatencyMs
@@ -84,36 +257,131 @@ func (r *Runner) checkRPC(ctx context.Context, p *config.RPCProvider) {
 		r.log.Error("insert rpc check", "provider", p.Name, "err", err)
 		return
 	}
-	metrics.RecordCheck("rpc", p.Name, res.Success, res.LatencyMs)
+	metrics.RecordCheck("rpc", p.Name, res.Success, res.LatencyMs, errCat)
+	r.recordCircuit("rpc", p.Name, res.Success)
 	if r.engine != nil {
-		if _, _, err := r.engine.ProcessRPCResult(ctx, p.Name, p.URL, res.Success, res.LatencyMs); err != nil {
+		if _, _, err := r.engine.ProcessRPCResult(ctx, p.Name, p.URL, res.Success); err != nil {
 			r.log.Error("process rpc incident", "provider", p.Name, "err", err)
 		}
 	}
-	r.log.Debug("rpc check", "provider", p.Name, "success", res.Success, "latency_ms", res.LatencyMs, "error", errCat)
+	r.log.Debug("rpc check", "provider", p.Name, "success", res.Success, "latency_ms", res.LatencyMs, "error", errCat, "mode", p.ConnectionMode, "probe", probe)
 }
 
 func (r *Runner) checkDapp(ctx context.Context, d *config.DappEndpoint) {
+	check, probe := r.circuitGate("dapp", d.Name)
+	if !check {
+		return
+	}
 	_, _ = r.store.EnsureDapp(ctx, d.Name, d.URL)
-	checker := httpcheck.NewChecker(d.URL, d.Timeout.Duration())
-	res := checker.Check(ctx)
+	var res httpcheck.Result
+	if probe {
+		res = r.dappCheckers[d.Name].Probe(ctx)
+	} else {
+		res = r.dappCheckers[d.Name].Check(ctx)
+	}
 	latPtr := (*int64)(nil)
 	if res.Success {
 		latPtr = &res.LatencyMs
 	}
-	errCat := ""
-	if !res.Success && res.Status > 0 {
-		errCat = "http_status"
+	errCat := res.ErrorCategory
+	steps := make([]store.CheckStep, 0, len(res.Steps))
+	for _, st := range res.Steps {
+		steps = append(steps, store.CheckStep{
+			Name: st.Name, Success: st.Success, Status: st.Status, LatencyMs: st.LatencyMs, ErrorCategory: st.ErrorCategory, Detail: st.Detail,
+		})
+		if !st.Success {
+			r.log.Info("dapp journey step failed", "dapp", d.Name, "step", st.Name, "status", st.Status, "error", st.ErrorCategory, "detail", st.Detail)
+		}
 	}
-	if err := r.store.InsertCheck(ctx, "dapp", d.Name, res.Success, latPtr, errCat); err != nil {
+	if err := r.store.InsertCheckWithSteps(ctx, "dapp", d.Name, res.Success, latPtr, errCat, steps); err != nil {
 		r.log.Error("insert dapp check", "dapp", d.Name, "err", err)
 		return
 	}
-	metrics.RecordCheck("dapp", d.Name, res.Success, res.LatencyMs)
+	metrics.RecordCheck("dapp", d.Name, res.Success, res.LatencyMs, errCat)
+	r.recordCircuit("dapp", d.Name, res.Success)
+	for _, a := range res.Assets {
+		switch {
+		case a.ErrorCategory == httpcheck.ErrorCategoryAssetHost:
+			r.log.Warn("dapp asset from unexpected host", "dapp", d.Name, "asset", a.URL, "kind", a.Kind)
+		case a.ErrorCategory == httpcheck.ErrorCategoryAssetHash:
+			r.log.Warn("dapp asset hash mismatch", "dapp", d.Name, "asset", a.URL, "kind", a.Kind)
+		case !a.Success:
+			r.log.Info("dapp asset failed", "dapp", d.Name, "asset", a.URL, "status", a.Status)
+		}
+	}
 	if r.engine != nil {
 		if _, _, err := r.engine.ProcessDappResult(ctx, d.Name, d.URL, res.Success); err != nil {
 			r.log.Error("process dapp incident", "dapp", d.Name, "err", err)
 		}
 	}
-	r.log.Debug("dapp check", "dapp", d.Name, "success", res.Success, "latency_ms", res.LatencyMs)
+	r.log.Debug("dapp check", "dapp", d.Name, "success", res.Success, "latency_ms", res.LatencyMs, "status", res.Status, "error", errCat, "mode", d.ConnectionMode, "assets", len(res.Assets), "steps", len(res.Steps), "probe", probe)
+}
+
+func (r *Runner) checkTCP(ctx context.Context, t *config.TCPTarget) {
+	check, probe := r.circuitGate("tcp", t.Name)
+	if !check {
+		return
+	}
+	var res tcpcheck.Result
+	if probe {
+		res = r.tcpCheckers[t.Name].Probe(ctx)
+	} else {
+		res = r.tcpCheckers[t.Name].Check(ctx)
+	}
+	latPtr := (*int64)(nil)
+	if res.Success {
+		latPtr = &res.LatencyMs
+	}
+	errCat := res.ErrorCategory
+	if err := r.store.InsertCheck(ctx, "tcp", t.Name, res.Success, latPtr, errCat); err != nil {
+		r.log.Error("insert tcp check", "target", t.Name, "err", err)
+		return
+	}
+	metrics.RecordCheck("tcp", t.Name, res.Success, res.LatencyMs, errCat)
+	r.recordCircuit("tcp", t.Name, res.Success)
+	if r.engine != nil {
+		if _, _, err := r.engine.ProcessTCPResult(ctx, t.Name, t.Address, res.Success); err != nil {
+			r.log.Error("process tcp incident", "target", t.Name, "err", err)
+		}
+	}
+	r.log.Debug("tcp check", "target", t.Name, "success", res.Success, "latency_ms", res.LatencyMs, "error", errCat, "tls", res.TLSVersion, "banner", res.Banner, "probe", probe)
+}
+
+func (r *Runner) checkNodeMetrics(ctx context.Context, n *config.NodeMetrics) {
+	// A scrape is already a single request, so an open circuit only slows it down.
+	if check, _ := r.circuitGate("node", n.Name); !check {
+		return
+	}
+	res := r.nodeCheckers[n.Name].Check(ctx)
+	latPtr := (*int64)(nil)
+	if res.Success {
+		latPtr = &res.LatencyMs
+	}
+	errCat := res.ErrorCategory
+	if err := r.store.InsertCheck(ctx, "node", n.Name, res.Success, latPtr, errCat); err != nil {
+		r.log.Error("insert node metrics check", "node", n.Name, "err", err)
+		return
+	}
+	metrics.RecordCheck("node", n.Name, res.Success, res.LatencyMs, errCat)
+	reachable := res.Success || errCat == promcheck.ErrorCategoryThreshold || errCat == promcheck.ErrorCategoryMissing ||
+		errCat == promcheck.ErrorCategoryStale
+	r.recordCircuit("node", n.Name, reachable)
+	for series, v := range res.Values {
+		metrics.SetNodeMetric(n.Name, series, v)
+	}
+	if r.engine != nil {
+		severity, summary := "", ""
+		if len(res.Violations) > 0 {
+			parts := make([]string, 0, len(res.Violations))
+			for _, v := range res.Violations {
+				parts = append(parts, v.String())
+			}
+			severity = res.Severity()
+			summary = "Node health rule violated: " + strings.Join(parts, "; ") + "."
+		}
+		if _, _, err := r.engine.ProcessNodeMetricsResult(ctx, n.Name, n.URL, res.Success, severity, summary); err != nil {
+			r.log.Error("process node metrics incident", "node", n.Name, "err", err)
+		}
+	}
+	r.log.Debug("node metrics check", "node", n.Name, "success", res.Success, "latency_ms", res.LatencyMs, "error", errCat, "values", res.Values)
 }
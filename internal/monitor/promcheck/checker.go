@@ -0,0 +1,289 @@
+package promcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/monitor/errcat"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	ErrorCategoryTimeout    = errcat.Timeout
+	ErrorCategoryDNS        = errcat.DNS
+	ErrorCategoryHTTP4xx    = errcat.HTTP4xx
+	ErrorCategoryHTTP5xx    = errcat.HTTP5xx
+	ErrorCategoryHTTPStatus = errcat.HTTPStatus
+	ErrorCategoryParse      = "parse"
+	ErrorCategoryFetch      = "fetch"
+	ErrorCategoryThreshold  = "threshold"
+	ErrorCategoryMissing    = "missing_series"
+	ErrorCategoryStale      = "stale_series"
+)
+
+// maxMetricsBytes caps how much of a /metrics response is parsed; a node's
+// exposition is a few MB at most.
+const maxMetricsBytes = 16 << 20
+
+const (
+	AggregateSum = "sum"
+	AggregateMax = "max"
+	AggregateMin = "min"
+)
+
+const (
+	SeverityWarn = "WARN"
+	SeverityCrit = "CRIT"
+)
+
+type Rule struct {
+	Name      string
+	Metric    string
+	Labels    map[string]string
+	Aggregate string
+	Op        string
+	Value     float64
+	Severity  string
+	// StaleAfter, when positive, also fails the rule once the value has not
+	// changed for that many consecutive scrapes, e.g. a state sync version
+	// that stopped advancing. Op may then be empty.
+	StaleAfter int
+}
+
+type Violation struct {
+	Rule     string
+	Metric   string
+	Value    float64
+	Op       string
+	Limit    float64
+	Severity string
+}
+
+func (v Violation) String() string {
+	if v.Op == "stale" {
+		return fmt.Sprintf("%s (%s=%g, unchanged for %g scrapes)", v.Rule, v.Metric, v.Value, v.Limit)
+	}
+	return fmt.Sprintf("%s (%s=%g, want %s %g)", v.Rule, v.Metric, v.Value, v.Op, v.Limit)
+}
+
+type Result struct {
+	Success       bool
+	LatencyMs     int64
+	ErrorCategory string
+	Values        map[string]float64
+	Violations    []Violation
+}
+
+type Checker struct {
+	URL        string
+	Rules      []Rule
+	HTTPClient *http.Client
+
+	mu sync.Mutex
+	// last and unchanged track each StaleAfter rule's value and how many
+	// scrapes in a row it has been the same.
+	last      map[string]float64
+	unchanged map[string]int
+}
+
+func NewChecker(url string, timeout time.Duration, rules []Rule) *Checker {
+	if timeout <= 0 {
+		timeout = 4 * time.Second
+	}
+	return &Checker{
+		URL:   url,
+		Rules: rules,
+		HTTPClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+func (c *Checker) Check(ctx context.Context) Result {
+	start := time.Now()
+	res := Result{}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		res.ErrorCategory = ErrorCategoryFetch
+		return res
+	}
+	req.Header.Set("Accept", "text/plain")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		res.LatencyMs = time.Since(start).Milliseconds()
+		res.ErrorCategory = errcat.Categorize(err)
+		return res
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if errCat := errcat.FromStatus(resp.StatusCode); errCat != "" {
+		res.LatencyMs = time.Since(start).Milliseconds()
+		res.ErrorCategory = errCat
+		return res
+	}
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(io.LimitReader(resp.Body, maxMetricsBytes))
+	res.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		res.ErrorCategory = ErrorCategoryParse
+		return res
+	}
+
+	res.Values = make(map[string]float64, len(c.Rules))
+	for _, rule := range c.Rules {
+		v, ok := extract(families, rule)
+		if !ok {
+			res.Violations = append(res.Violations, Violation{
+				Rule: rule.Name, Metric: rule.Metric, Op: "present", Severity: rule.Severity,
+			})
+			continue
+		}
+		res.Values[rule.Name] = v
+		if !compare(v, rule.Op, rule.Value) {
+			res.Violations = append(res.Violations, Violation{
+				Rule: rule.Name, Metric: rule.Metric, Value: v, Op: rule.Op, Limit: rule.Value, Severity: rule.Severity,
+			})
+		} else if n := c.track(rule, v); rule.StaleAfter > 0 && n >= rule.StaleAfter {
+			res.Violations = append(res.Violations, Violation{
+				Rule: rule.Name, Metric: rule.Metric, Value: v, Op: "stale", Limit: float64(n), Severity: rule.Severity,
+			})
+		}
+	}
+	if len(res.Violations) > 0 {
+		// A missing series outranks a threshold, which outranks staleness.
+		for _, v := range res.Violations {
+			switch {
+			case v.Op == "present":
+				res.ErrorCategory = ErrorCategoryMissing
+			case v.Op != "stale" && res.ErrorCategory != ErrorCategoryMissing:
+				res.ErrorCategory = ErrorCategoryThreshold
+			case res.ErrorCategory == "":
+				res.ErrorCategory = ErrorCategoryStale
+			}
+		}
+		return res
+	}
+	res.Success = true
+	return res
+}
+
+// track records a StaleAfter rule's value and returns how many scrapes in a
+// row it has not changed.
+func (c *Checker) track(rule Rule, v float64) int {
+	if rule.StaleAfter <= 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last == nil {
+		c.last = make(map[string]float64)
+		c.unchanged = make(map[string]int)
+	}
+	if last, ok := c.last[rule.Name]; ok && last == v {
+		c.unchanged[rule.Name]++
+	} else {
+		c.unchanged[rule.Name] = 0
+	}
+	c.last[rule.Name] = v
+	return c.unchanged[rule.Name]
+}
+
+func (r *Result) Severity() string {
+	sev := ""
+	for _, v := range r.Violations {
+		if v.Severity == SeverityCrit {
+			return SeverityCrit
+		}
+		sev = SeverityWarn
+	}
+	return sev
+}
+
+func extract(families map[string]*dto.MetricFamily, rule Rule) (float64, bool) {
+	mf, ok := families[rule.Metric]
+	if !ok {
+		return 0, false
+	}
+	var out float64
+	found := false
+	for _, m := range mf.GetMetric() {
+		if !labelsMatch(m, rule.Labels) {
+			continue
+		}
+		v, ok := sampleValue(mf.GetType(), m)
+		if !ok {
+			continue
+		}
+		if !found {
+			out = v
+			found = true
+			continue
+		}
+		switch rule.Aggregate {
+		case AggregateMax:
+			if v > out {
+				out = v
+			}
+		case AggregateMin:
+			if v < out {
+				out = v
+			}
+		default:
+			out += v
+		}
+	}
+	return out, found
+}
+
+func labelsMatch(m *dto.Metric, want map[string]string) bool {
+	for k, v := range want {
+		matched := false
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == k && lp.GetValue() == v {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func sampleValue(t dto.MetricType, m *dto.Metric) (float64, bool) {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetValue(), true
+	case dto.MetricType_GAUGE:
+		return m.GetGauge().GetValue(), true
+	case dto.MetricType_UNTYPED:
+		return m.GetUntyped().GetValue(), true
+	default:
+		return 0, false
+	}
+}
+
+func compare(v float64, op string, limit float64) bool {
+	switch op {
+	case "<":
+		return v < limit
+	case "<=":
+		return v <= limit
+	case ">":
+		return v > limit
+	case ">=":
+		return v >= limit
+	case "==":
+		return v == limit
+	case "!=":
+		return v != limit
+	default:
+		return true
+	}
+}
@@ -0,0 +1,122 @@
+package promcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const sampleMetrics = `# HELP aptos_connections Number of current connections
+# TYPE aptos_connections gauge
+aptos_connections{direction="inbound",network_id="Public"} 3
+aptos_connections{direction="outbound",network_id="Public"} 5
+# TYPE aptos_state_sync_version gauge
+aptos_state_sync_version{type="synced"} 123456
+aptos_state_sync_version{type="committed"} 123450
+`
+
+func metricsServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestChecker_Check_RulesPass(t *testing.T) {
+	server := metricsServer(sampleMetrics)
+	defer server.Close()
+	rules := []Rule{
+		{Name: "peers", Metric: "aptos_connections", Aggregate: AggregateSum, Op: ">=", Value: 1, Severity: SeverityCrit},
+		{Name: "synced", Metric: "aptos_state_sync_version", Labels: map[string]string{"type": "synced"}, Op: ">", Value: 0, Severity: SeverityWarn},
+	}
+	res := NewChecker(server.URL, 0, rules).Check(context.Background())
+	if !res.Success {
+		t.Fatalf("expected success: %+v", res)
+	}
+	if res.Values["peers"] != 8 {
+		t.Errorf("peers = %v, want 8", res.Values["peers"])
+	}
+	if res.Values["synced"] != 123456 {
+		t.Errorf("synced = %v", res.Values["synced"])
+	}
+}
+
+func TestChecker_Check_Violation(t *testing.T) {
+	server := metricsServer(`aptos_connections{direction="outbound"} 0` + "\n")
+	defer server.Close()
+	rules := []Rule{
+		{Name: "peers", Metric: "aptos_connections", Op: ">=", Value: 1, Severity: SeverityCrit},
+		{Name: "round", Metric: "aptos_consensus_current_round", Op: ">", Value: 0, Severity: SeverityWarn},
+	}
+	res := NewChecker(server.URL, 0, rules).Check(context.Background())
+	if res.Success {
+		t.Fatal("expected failure")
+	}
+	if len(res.Violations) != 2 {
+		t.Fatalf("violations = %+v", res.Violations)
+	}
+	if res.ErrorCategory != ErrorCategoryMissing {
+		t.Errorf("error_category = %q", res.ErrorCategory)
+	}
+	if res.Severity() != SeverityCrit {
+		t.Errorf("severity = %q", res.Severity())
+	}
+}
+
+func TestChecker_Check_HTTPStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	res := NewChecker(server.URL, 0, nil).Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryHTTP5xx {
+		t.Errorf("expected http_5xx failure: %+v", res)
+	}
+}
+
+func TestChecker_Check_Stale(t *testing.T) {
+	var version atomic.Int64
+	version.Store(100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "aptos_state_sync_version{type=\"synced\"} %d\n", version.Load())
+	}))
+	defer server.Close()
+	rules := []Rule{{Name: "synced", Metric: "aptos_state_sync_version", Labels: map[string]string{"type": "synced"}, StaleAfter: 2, Severity: SeverityWarn}}
+	c := NewChecker(server.URL, 0, rules)
+	for i := 0; i < 2; i++ {
+		if res := c.Check(context.Background()); !res.Success {
+			t.Fatalf("scrape %d: unchanged for %d scrapes should pass: %+v", i+1, i, res)
+		}
+	}
+	res := c.Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryStale || !strings.Contains(res.Violations[0].String(), "unchanged for 2 scrapes") {
+		t.Fatalf("expected stale failure: %+v", res)
+	}
+	version.Store(101)
+	if res := c.Check(context.Background()); !res.Success {
+		t.Errorf("an advancing version should pass: %+v", res)
+	}
+}
+
+func TestChecker_Check_BodyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		line := []byte("aptos_connections 1\n")
+		for n := 0; n <= maxMetricsBytes; n += len(line) {
+			if _, err := w.Write(line); err != nil {
+				return
+			}
+		}
+		_, _ = w.Write([]byte("aptos_consensus_current_round 7\n"))
+	}))
+	defer server.Close()
+	rules := []Rule{{Name: "round", Metric: "aptos_consensus_current_round", Op: ">", Value: 0}}
+	res := NewChecker(server.URL, 10*time.Second, rules).Check(context.Background())
+	if res.Success {
+		t.Errorf("series past the size limit should not be read: %+v", res)
+	}
+}
@@ -3,27 +3,37 @@ package rpc
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/monitor/errcat"
 )
 
 const (
-	ErrorCategoryTimeout           = "timeout"
-	ErrorCategoryDNS               = "dns"
-	ErrorCategoryTLS               = "tls"
-	ErrorCategoryHTTPStatus        = "http_status"
-	ErrorCategoryJSONDecode        = "json_decode"
-	ErrorCategoryUnexpectedPayload = "unexpected_payload"
+	ErrorCategoryTimeout           = errcat.Timeout
+	ErrorCategoryDNS               = errcat.DNS
+	ErrorCategoryTLS               = errcat.TLS
+	ErrorCategoryConnRefused       = errcat.ConnRefused
+	ErrorCategoryConnReset         = errcat.ConnReset
+	ErrorCategoryHTTPStatus        = errcat.HTTPStatus
+	ErrorCategoryHTTP4xx           = errcat.HTTP4xx
+	ErrorCategoryHTTP5xx           = errcat.HTTP5xx
+	ErrorCategoryBodyRead          = errcat.BodyRead
+	ErrorCategoryJSONDecode        = errcat.JSONDecode
+	ErrorCategoryUnexpectedPayload = errcat.UnexpectedPayload
+	ErrorCategoryNodeUnhealthy     = "node_unhealthy"
 )
 
 type Result struct {
 	Success       bool
 	LatencyMs     int64
 	ErrorCategory string
+	// Requests is how many HTTP requests the check sent, for provider quotas.
+	Requests      int
 	ChainID       int
 	LedgerVersion uint64
 	BlockHeight   uint64
@@ -33,6 +43,10 @@ type Result struct {
 type Checker struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	// HealthyDurationSecs enables the /v1/-/healthy?duration_secs=N probe when > 0.
+	HealthyDurationSecs int
+	// Cold drops idle connections before each check so it pays for DNS, TCP and TLS again.
+	Cold bool
 }
 
 func NewChecker(baseURL string, timeout time.Duration) *Checker {
@@ -51,6 +65,9 @@ func NewChecker(baseURL string, timeout time.Duration) *Checker {
 }
 
 func (c *Checker) Check(ctx context.Context) Result {
+	if c.Cold {
+		c.HTTPClient.CloseIdleConnections()
+	}
 	start := time.Now()
 	res := Result{}
 
@@ -62,6 +79,7 @@ func (c *Checker) Check(ctx context.Context) Result {
 		res.LatencyMs = time.Since(start).Milliseconds()
 		return res
 	}
+	res.Requests++
 	resp1, err := c.HTTPClient.Do(req1)
 	if err != nil {
 		res.LatencyMs = time.Since(start).Milliseconds()
@@ -72,12 +90,12 @@ func (c *Checker) Check(ctx context.Context) Result {
 	_ = resp1.Body.Close()
 	if err != nil {
 		res.LatencyMs = time.Since(start).Milliseconds()
-		res.ErrorCategory = ErrorCategoryUnexpectedPayload
+		res.ErrorCategory = ErrorCategoryBodyRead
 		return res
 	}
-	if resp1.StatusCode < 200 || resp1.StatusCode >= 400 {
+	if errCat := errcat.FromStatus(resp1.StatusCode); errCat != "" {
 		res.LatencyMs = time.Since(start).Milliseconds()
-		res.ErrorCategory = ErrorCategoryHTTPStatus
+		res.ErrorCategory = errCat
 		return res
 	}
 	var v1 map[string]interface{}
@@ -98,6 +116,7 @@ func (c *Checker) Check(ctx context.Context) Result {
 		res.LatencyMs = time.Since(start).Milliseconds()
 		return res
 	}
+	res.Requests++
 	resp2, err := c.HTTPClient.Do(req2)
 	if err != nil {
 		res.LatencyMs = time.Since(start).Milliseconds()
@@ -108,12 +127,12 @@ func (c *Checker) Check(ctx context.Context) Result {
 	_ = resp2.Body.Close()
 	if err != nil {
 		res.LatencyMs = time.Since(start).Milliseconds()
-		res.ErrorCategory = ErrorCategoryUnexpectedPayload
+		res.ErrorCategory = ErrorCategoryBodyRead
 		return res
 	}
-	if resp2.StatusCode < 200 || resp2.StatusCode >= 400 {
+	if errCat := errcat.FromStatus(resp2.StatusCode); errCat != "" {
 		res.LatencyMs = time.Since(start).Milliseconds()
-		res.ErrorCategory = ErrorCategoryHTTPStatus
+		res.ErrorCategory = errCat
 		return res
 	}
 	var ledger map[string]interface{}
@@ -134,11 +153,78 @@ func (c *Checker) Check(ctx context.Context) Result {
 		}
 	}
 
+	// Latency covers /v1 and /v1/ledger_info only, so enabling the healthy
+	// probe does not shift latency incidents, SLOs or uptime percentiles.
+	res.LatencyMs = time.Since(start).Milliseconds()
+	if c.HealthyDurationSecs > 0 {
+		res.Requests++
+		if errCat := c.probeHealthy(ctx); errCat != "" {
+			res.ErrorCategory = errCat
+			return res
+		}
+	}
+
 	res.Success = true
+	return res
+}
+
+// RequestsPerCheck is the number of requests a full Check sends when every
+// step is reached.
+func (c *Checker) RequestsPerCheck() int {
+	if c.HealthyDurationSecs > 0 {
+		return 3
+	}
+	return 2
+}
+
+// Probe is the lightweight check used while the endpoint's circuit is open:
+// a single GET /v1 that only has to answer 2xx.
+func (c *Checker) Probe(ctx context.Context) Result {
+	start := time.Now()
+	res := Result{}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/v1", nil)
+	if err != nil {
+		res.ErrorCategory = ErrorCategoryUnexpectedPayload
+		return res
+	}
+	res.Requests++
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		res.LatencyMs = time.Since(start).Milliseconds()
+		res.ErrorCategory = categorizeErr(err)
+		return res
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
 	res.LatencyMs = time.Since(start).Milliseconds()
+	if errCat := errcat.FromStatus(resp.StatusCode); errCat != "" {
+		res.ErrorCategory = errCat
+		return res
+	}
+	res.Success = true
 	return res
 }
 
+// GET /v1/-/healthy?duration_secs=N answers 200 only if the node has committed
+// a ledger version within the last N seconds.
+func (c *Checker) probeHealthy(ctx context.Context) string {
+	url := c.BaseURL + "/v1/-/healthy?duration_secs=" + strconv.Itoa(c.HealthyDurationSecs)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ErrorCategoryUnexpectedPayload
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return categorizeErr(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrorCategoryNodeUnhealthy
+	}
+	return ""
+}
+
 func getNumber(m map[string]interface{}, key string) (int64, bool) {
 	v, ok := m[key]
 	if !ok {
@@ -157,26 +243,7 @@ func getNumber(m map[string]interface{}, key string) (int64, bool) {
 }
 
 func categorizeErr(err error) string {
-	if err == nil {
-		return ""
-	}
-	var netErr *net.DNSError
-	if errors.As(err, &netErr) {
-		return ErrorCategoryDNS
-	}
-	var netOpErr *net.OpError
-	if errors.As(err, &netOpErr) {
-		if netOpErr.Op == "dial" && strings.Contains(err.Error(), "TLS") {
-			return ErrorCategoryTLS
-		}
-	}
-	if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "deadline") {
-		return ErrorCategoryTimeout
-	}
-	if strings.Contains(err.Error(), "tls") || strings.Contains(err.Error(), "certificate") {
-		return ErrorCategoryTLS
-	}
-	return ErrorCategoryUnexpectedPayload
+	return errcat.Categorize(err)
 }
 
 func (r *Result) ErrorSummary() string {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestChecker_Check_Success(t *testing.T) {
@@ -59,7 +60,7 @@ func TestChecker_Check_HTTPStatus(t *testing.T) {
 	if res.Success {
 		t.Fatal("expected failure")
 	}
-	if res.ErrorCategory != ErrorCategoryHTTPStatus {
+	if res.ErrorCategory != ErrorCategoryHTTP5xx {
 		t.Errorf("error_category = %q", res.ErrorCategory)
 	}
 }
@@ -83,3 +84,68 @@ func TestChecker_Check_JSONDecode(t *testing.T) {
 		t.Errorf("error_category = %q", res.ErrorCategory)
 	}
 }
+
+func TestChecker_Check_NodeUnhealthy(t *testing.T) {
+	var gotDuration string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1":
+			_, _ = w.Write([]byte(`{"chain_id":1}`))
+		case "/v1/ledger_info":
+			_, _ = w.Write([]byte(`{"ledger_version":"1"}`))
+		case "/v1/-/healthy":
+			gotDuration = r.URL.Query().Get("duration_secs")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"The latest ledger info timestamp is too old"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	checker := NewChecker(server.URL, 0)
+	if res := checker.Check(context.Background()); !res.Success || res.Requests != 2 {
+		t.Fatalf("healthy probe disabled, expected success in 2 requests: %+v", res)
+	}
+	checker.HealthyDurationSecs = 30
+	res := checker.Check(context.Background())
+	if res.Success {
+		t.Fatal("expected failure")
+	}
+	if res.Requests != checker.RequestsPerCheck() {
+		t.Errorf("requests = %d, want %d", res.Requests, checker.RequestsPerCheck())
+	}
+	if res.ErrorCategory != ErrorCategoryNodeUnhealthy {
+		t.Errorf("error_category = %q", res.ErrorCategory)
+	}
+	if gotDuration != "30" {
+		t.Errorf("duration_secs = %q", gotDuration)
+	}
+}
+
+func TestChecker_Check_HealthyProbeNotInLatency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1":
+			_, _ = w.Write([]byte(`{"chain_id":1}`))
+		case "/v1/ledger_info":
+			_, _ = w.Write([]byte(`{"ledger_version":"1"}`))
+		case "/v1/-/healthy":
+			time.Sleep(300 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"message":"aptos-node:ok"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	checker := NewChecker(server.URL, 0)
+	checker.HealthyDurationSecs = 30
+	res := checker.Check(context.Background())
+	if !res.Success || res.Requests != 3 {
+		t.Fatalf("expected success in 3 requests: %+v", res)
+	}
+	if res.LatencyMs >= 300 {
+		t.Errorf("latency = %d ms, should not include the slow healthy probe", res.LatencyMs)
+	}
+}
@@ -0,0 +1,158 @@
+package tcpcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/monitor/errcat"
+)
+
+const (
+	ErrorCategoryTimeout     = errcat.Timeout
+	ErrorCategoryDNS         = errcat.DNS
+	ErrorCategoryConnRefused = errcat.ConnRefused
+	ErrorCategoryConnReset   = errcat.ConnReset
+	ErrorCategoryTLS         = errcat.TLS
+	ErrorCategoryBanner      = "banner"
+	ErrorCategoryDial        = "dial"
+)
+
+const maxBannerBytes = 256
+
+type Result struct {
+	Success       bool
+	LatencyMs     int64
+	ErrorCategory string
+	Banner        string
+	TLSVersion    string
+}
+
+type Checker struct {
+	Address      string
+	Network      string
+	Timeout      time.Duration
+	TLS          bool
+	ServerName   string
+	ReadBanner   bool
+	ExpectBanner string
+	Dialer       *net.Dialer
+}
+
+func NewChecker(address string, timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 4 * time.Second
+	}
+	return &Checker{
+		Address: address,
+		Network: "tcp",
+		Timeout: timeout,
+		Dialer:  &net.Dialer{Timeout: timeout},
+	}
+}
+
+// Probe is the lightweight check used while the target's circuit is open:
+// a bare connect with no TLS handshake or banner read.
+func (c *Checker) Probe(ctx context.Context) Result {
+	light := *c
+	light.TLS = false
+	light.ReadBanner = false
+	light.ExpectBanner = ""
+	return light.Check(ctx)
+}
+
+func (c *Checker) Check(ctx context.Context) Result {
+	start := time.Now()
+	res := Result{}
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	network := c.Network
+	if network == "" {
+		network = "tcp"
+	}
+	conn, err := c.Dialer.DialContext(ctx, network, c.Address)
+	if err != nil {
+		res.LatencyMs = time.Since(start).Milliseconds()
+		res.ErrorCategory = categorizeErr(err)
+		return res
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if c.TLS {
+		serverName := c.ServerName
+		if serverName == "" {
+			serverName, _, _ = net.SplitHostPort(c.Address)
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: serverName})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			res.LatencyMs = time.Since(start).Milliseconds()
+			if isTimeout(err) {
+				res.ErrorCategory = ErrorCategoryTimeout
+			} else {
+				res.ErrorCategory = ErrorCategoryTLS
+			}
+			return res
+		}
+		res.TLSVersion = tls.VersionName(tlsConn.ConnectionState().Version)
+		conn = tlsConn
+	}
+
+	if c.ReadBanner || c.ExpectBanner != "" {
+		buf := make([]byte, maxBannerBytes)
+		n, err := conn.Read(buf)
+		res.Banner = strings.TrimSpace(string(buf[:n]))
+		if n == 0 && err != nil {
+			res.LatencyMs = time.Since(start).Milliseconds()
+			switch {
+			case isTimeout(err):
+				res.ErrorCategory = ErrorCategoryTimeout
+			case errcat.Categorize(err) == ErrorCategoryConnReset:
+				res.ErrorCategory = ErrorCategoryConnReset
+			default:
+				res.ErrorCategory = ErrorCategoryBanner
+			}
+			return res
+		}
+		if c.ExpectBanner != "" && !strings.Contains(res.Banner, c.ExpectBanner) {
+			res.LatencyMs = time.Since(start).Milliseconds()
+			res.ErrorCategory = ErrorCategoryBanner
+			return res
+		}
+	}
+
+	res.Success = true
+	res.LatencyMs = time.Since(start).Milliseconds()
+	return res
+}
+
+func categorizeErr(err error) string {
+	if errCat := errcat.Categorize(err); errCat != errcat.UnexpectedPayload {
+		return errCat
+	}
+	return ErrorCategoryDial
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (r *Result) ErrorSummary() string {
+	if r.Success {
+		return ""
+	}
+	if r.ErrorCategory != "" {
+		return r.ErrorCategory
+	}
+	return "unknown"
+}
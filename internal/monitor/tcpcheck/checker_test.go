@@ -0,0 +1,75 @@
+package tcpcheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func listen(t *testing.T, banner string) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			if banner != "" {
+				_, _ = conn.Write([]byte(banner))
+			}
+			_ = conn.Close()
+		}
+	}()
+	return ln
+}
+
+func TestChecker_Check_Success(t *testing.T) {
+	ln := listen(t, "")
+	defer func() { _ = ln.Close() }()
+	checker := NewChecker(ln.Addr().String(), 0)
+	res := checker.Check(context.Background())
+	if !res.Success {
+		t.Fatalf("expected success: %+v", res)
+	}
+	if res.LatencyMs < 0 {
+		t.Errorf("latency_ms = %d", res.LatencyMs)
+	}
+}
+
+func TestChecker_Check_ConnRefused(t *testing.T) {
+	ln := listen(t, "")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	checker := NewChecker(addr, time.Second)
+	res := checker.Check(context.Background())
+	if res.Success {
+		t.Fatal("expected failure")
+	}
+	if res.ErrorCategory != ErrorCategoryConnRefused {
+		t.Errorf("error_category = %q", res.ErrorCategory)
+	}
+}
+
+func TestChecker_Check_Banner(t *testing.T) {
+	ln := listen(t, "SSH-2.0-test\r\n")
+	defer func() { _ = ln.Close() }()
+	checker := NewChecker(ln.Addr().String(), time.Second)
+	checker.ExpectBanner = "SSH-2.0"
+	res := checker.Check(context.Background())
+	if !res.Success {
+		t.Fatalf("expected success: %+v", res)
+	}
+	if res.Banner != "SSH-2.0-test" {
+		t.Errorf("banner = %q", res.Banner)
+	}
+	checker.ExpectBanner = "HTTP"
+	res = checker.Check(context.Background())
+	if res.Success || res.ErrorCategory != ErrorCategoryBanner {
+		t.Errorf("expected banner mismatch: %+v", res)
+	}
+}
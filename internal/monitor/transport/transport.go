@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+type Options struct {
+	ProxyURL           string
+	CAFile             string
+	ClientCertFile     string
+	ClientKeyFile      string
+	InsecureSkipVerify bool
+	// Network forces the dial family: "tcp4", "tcp6", or "" / "tcp" for either.
+	// A forced family dials the target directly: through a proxy it would
+	// only pick the family of the proxy connection. It cannot be combined
+	// with ProxyURL, and proxy environment variables are ignored.
+	Network string
+}
+
+func New(opts Options) (*http.Transport, error) {
+	dialer := &net.Dialer{Timeout: 3 * time.Second}
+	t := &http.Transport{
+		Proxy:       http.ProxyFromEnvironment,
+		DialContext: dialer.DialContext,
+	}
+	if opts.Network != "" && opts.Network != "tcp" {
+		if opts.ProxyURL != "" {
+			return nil, fmt.Errorf("proxy_url cannot be used with a forced IP family")
+		}
+		network := opts.Network
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+	if opts.ProxyURL != "" {
+		u, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("proxy_url: %w", err)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	tlsCfg, err := TLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsCfg
+	return t, nil
+}
+
+func TLSConfig(opts Options) (*tls.Config, error) {
+	if opts.CAFile == "" && opts.ClientCertFile == "" && !opts.InsecureSkipVerify {
+		return nil, nil
+	}
+	cfg := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_file: no certificates found in %s", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if opts.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client_cert_file: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,161 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func clientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "guardian"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("cert: %v", err)
+	}
+	cert, _ = x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile, cert
+}
+
+func TestNew_CustomCAAndClientCert(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, cert := clientCert(t, dir)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+
+	noCert, err := New(Options{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if resp, err := (&http.Client{Transport: noCert}).Get(server.URL); err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected handshake failure without client certificate")
+	}
+
+	tr, err := New(Options{CAFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("mTLS request: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestNew_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	tr, err := New(Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := New(Options{CAFile: "/nonexistent/ca.pem"}); err == nil {
+		t.Error("expected error for missing ca_file")
+	}
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	_ = os.WriteFile(empty, []byte("not a cert"), 0600)
+	if _, err := New(Options{CAFile: empty}); err == nil {
+		t.Error("expected error for ca_file without certificates")
+	}
+}
+
+func TestNew_ForcedNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	v4, err := New(Options{Network: "tcp4"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	resp, err := (&http.Client{Transport: v4}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("tcp4 request to IPv4 listener: %v", err)
+	}
+	_ = resp.Body.Close()
+	v6, err := New(Options{Network: "tcp6"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if resp, err := (&http.Client{Transport: v6}).Get(server.URL); err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("tcp6 request to IPv4 listener should fail")
+	}
+}
+
+func TestNew_ForcedNetworkBypassesProxy(t *testing.T) {
+	tr, err := New(Options{})
+	if err != nil || tr.Proxy == nil {
+		t.Fatalf("default transport should honour proxy environment variables: %v", err)
+	}
+	tr, err = New(Options{Network: "tcp6"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if tr.Proxy != nil {
+		t.Error("a forced family must dial the target, not a proxy")
+	}
+	if _, err := New(Options{Network: "tcp4", ProxyURL: "http://proxy.example:3128"}); err == nil {
+		t.Error("expected error for proxy_url with a forced family")
+	}
+}
@@ -16,7 +16,22 @@ type CheckRow struct {
 	CreatedAt     time.Time
 }
 
+type CheckStep struct {
+	Name          string
+	Success       bool
+	Status        int
+	LatencyMs     int64
+	ErrorCategory string
+	Detail        string
+}
+
 func (s *Store) InsertCheck(ctx context.Context, entityType, entityName string, success bool, latencyMs *int64, errorCategory string) error {
+	return s.InsertCheckWithSteps(ctx, entityType, entityName, success, latencyMs, errorCategory, nil)
+}
+
+// InsertCheckWithSteps records one check together with the timings of the
+// journey steps that made it up.
+func (s *Store) InsertCheckWithSteps(ctx context.Context, entityType, entityName string, success bool, latencyMs *int64, errorCategory string, steps []CheckStep) error {
 	var lat sql.NullInt64
 	if latencyMs != nil {
 		lat = sql.NullInt64{Int64: *latencyMs, Valid: true}
@@ -25,20 +40,104 @@ func (s *Store) InsertCheck(ctx context.Context, entityType, entityName string,
 	if errorCategory != "" {
 		errCat = sql.NullString{String: errorCategory, Valid: true}
 	}
-	_, err := s.db.ExecContext(ctx,
+	if len(steps) == 0 {
+		_, err := s.db.ExecContext(ctx,
+			`INSERT INTO checks (entity_type, entity_name, success, latency_ms, error_category) VALUES (?, ?, ?, ?, ?)`,
+			entityType, entityName, success, lat, errCat)
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.ExecContext(ctx,
 		`INSERT INTO checks (entity_type, entity_name, success, latency_ms, error_category) VALUES (?, ?, ?, ?, ?)`,
 		entityType, entityName, success, lat, errCat)
-	return err
+	if err != nil {
+		return err
+	}
+	checkID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for i, st := range steps {
+		var status sql.NullInt64
+		if st.Status != 0 {
+			status = sql.NullInt64{Int64: int64(st.Status), Valid: true}
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO check_steps (check_id, position, name, success, status, latency_ms, error_category, detail) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			checkID, i, st.Name, st.Success, status, st.LatencyMs, nullString(st.ErrorCategory), nullString(st.Detail)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) CheckSteps(ctx context.Context, checkID int64) ([]CheckStep, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name, success, status, latency_ms, error_category, detail FROM check_steps WHERE check_id = ? ORDER BY position`,
+		checkID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []CheckStep
+	for rows.Next() {
+		var st CheckStep
+		var successInt int64
+		var status sql.NullInt64
+		var errCat, detail sql.NullString
+		if err := rows.Scan(&st.Name, &successInt, &status, &st.LatencyMs, &errCat, &detail); err != nil {
+			return nil, err
+		}
+		st.Success = successInt != 0
+		st.Status = int(status.Int64)
+		st.ErrorCategory = errCat.String
+		st.Detail = detail.String
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// CheckCounts counts the checks of an entity created at or after since and
+// how many of them were good: successful and, when maxLatencyMs > 0, no
+// slower than maxLatencyMs.
+func (s *Store) CheckCounts(ctx context.Context, entityType, entityName string, since time.Time, maxLatencyMs int) (good, total int, err error) {
+	var goodN sql.NullInt64
+	err = s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), SUM(CASE WHEN success = 1 AND (? <= 0 OR latency_ms <= ?) THEN 1 ELSE 0 END)
+		 FROM checks WHERE entity_type = ? AND entity_name = ? AND created_at >= ?`,
+		maxLatencyMs, maxLatencyMs, entityType, entityName, since.UTC().Format(sqlTime)).Scan(&total, &goodN)
+	return int(goodN.Int64), total, err
 }
 
 func (s *Store) RecentChecks(ctx context.Context, entityType, entityName string, limit int) ([]CheckRow, error) {
 	if limit <= 0 {
 		limit = 100
 	}
-	rows, err := s.db.QueryContext(ctx,
+	return s.queryChecks(ctx,
 		`SELECT id, entity_type, entity_name, success, latency_ms, error_category, created_at
 		 FROM checks WHERE entity_type = ? AND entity_name = ? ORDER BY id DESC LIMIT ?`,
 		entityType, entityName, limit)
+}
+
+// ChecksBetween returns the checks of an entity created between from and to
+// inclusive, oldest first.
+func (s *Store) ChecksBetween(ctx context.Context, entityType, entityName string, from, to time.Time) ([]CheckRow, error) {
+	return s.queryChecks(ctx,
+		`SELECT id, entity_type, entity_name, success, latency_ms, error_category, created_at
+		 FROM checks WHERE entity_type = ? AND entity_name = ? AND created_at >= ? AND created_at <= ? ORDER BY created_at, id`,
+		entityType, entityName, from.UTC().Format(sqlTime), to.UTC().Format(sqlTime))
+}
+
+func (s *Store) queryChecks(ctx context.Context, query string, args ...interface{}) ([]CheckRow, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
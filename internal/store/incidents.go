@@ -6,6 +6,17 @@ import (
 	"time"
 )
 
+// Incident lifecycle states, Statuspage style. Every state but resolved
+// counts as open.
+const (
+	IncidentStateInvestigating = "investigating"
+	IncidentStateIdentified    = "identified"
+	IncidentStateMonitoring    = "monitoring"
+	IncidentStateResolved      = "resolved"
+)
+
+// IncidentStateOpen and IncidentStateClosed are list filters: open matches
+// every unresolved state and closed matches resolved.
 const (
 	IncidentStateOpen   = "open"
 	IncidentStateClosed = "closed"
@@ -13,48 +24,262 @@ const (
 	SeverityCrit        = "CRIT"
 )
 
+func ValidIncidentState(state string) bool {
+	switch state {
+	case IncidentStateInvestigating, IncidentStateIdentified, IncidentStateMonitoring, IncidentStateResolved:
+		return true
+	}
+	return false
+}
+
 type Incident struct {
-	ID         int64
-	EntityType string
-	EntityName string
-	EntityURL  string
-	State      string
-	Severity   string
-	Summary    string
-	StartedAt  time.Time
-	EndedAt    *time.Time
-	CreatedAt  time.Time
+	ID             int64
+	EntityType     string
+	EntityName     string
+	EntityURL      string
+	State          string
+	Acknowledged   bool
+	Severity       string
+	Summary        string
+	StartedAt      time.Time
+	EndedAt        *time.Time
+	StateChangedAt time.Time
+	// SeverityChangedAt is when the severity last changed, or StartedAt.
+	SeverityChangedAt time.Time
+	// Flapping incidents are held open across recoveries; FlapCount counts
+	// the open/close cycles and FlapRecoveredAt is set while checks pass.
+	Flapping        bool
+	FlapCount       int
+	FlapRecoveredAt *time.Time
+	// Maintenance marks incidents opened during a maintenance window.
+	Maintenance bool
+	// ParentID links the incident to a correlated parent incident, or is 0.
+	ParentID int64
+	// ImpactedBy is the open upstream incident of a dependency when this
+	// incident opened, or 0 once it is judged on its own.
+	ImpactedBy int64
+	// Manual incidents were opened through the admin API; checks do not
+	// re-grade or close them.
+	Manual bool
+	// ReportCount counts user reports linked to the incident or its children.
+	ReportCount int
+	CreatedAt   time.Time
+}
+
+func (i *Incident) Open() bool {
+	return i.State != IncidentStateResolved
 }
 
 type IncidentUpdate struct {
 	ID         int64
 	IncidentID int64
-	Message    string
-	CreatedAt  time.Time
+	// State is the incident's state when the update was written.
+	State string
+	// FromSeverity and ToSeverity are set on updates that record a severity
+	// change.
+	FromSeverity, ToSeverity string
+	Message                  string
+	CreatedAt                time.Time
+}
+
+const incidentColumns = `id, entity_type, entity_name, entity_url, state, acknowledged, severity, summary, started_at, ended_at, state_changed_at, severity_changed_at, flapping, flap_count, flap_recovered_at, maintenance, parent_id, impacted_by, manual, created_at, ` +
+	`(SELECT COUNT(*) FROM reports r WHERE r.incident_id = incidents.id OR r.incident_id IN (SELECT c.id FROM incidents c WHERE c.parent_id = incidents.id))`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanIncident(row rowScanner) (*Incident, error) {
+	var i Incident
+	var entityURL, startedAt, endedAt, stateChangedAt, severityChangedAt, flapRecoveredAt, createdAt sql.NullString
+	var ack, flapping, maintenance, manual int64
+	var parentID, impactedBy sql.NullInt64
+	if err := row.Scan(&i.ID, &i.EntityType, &i.EntityName, &entityURL, &i.State, &ack, &i.Severity, &i.Summary,
+		&startedAt, &endedAt, &stateChangedAt, &severityChangedAt, &flapping, &i.FlapCount, &flapRecoveredAt, &maintenance, &parentID, &impactedBy, &manual, &createdAt, &i.ReportCount); err != nil {
+		return nil, err
+	}
+	i.EntityURL = entityURL.String
+	i.Acknowledged = ack != 0
+	i.Flapping = flapping != 0
+	i.Maintenance = maintenance != 0
+	i.ParentID = parentID.Int64
+	i.ImpactedBy = impactedBy.Int64
+	i.Manual = manual != 0
+	if startedAt.Valid {
+		if t, ok := parseTime(startedAt.String); ok {
+			i.StartedAt = t
+		}
+	}
+	if endedAt.Valid {
+		if t, ok := parseTime(endedAt.String); ok {
+			i.EndedAt = &t
+		}
+	}
+	if stateChangedAt.Valid {
+		if t, ok := parseTime(stateChangedAt.String); ok {
+			i.StateChangedAt = t
+		}
+	}
+	if i.StateChangedAt.IsZero() {
+		i.StateChangedAt = i.StartedAt
+	}
+	if severityChangedAt.Valid {
+		if t, ok := parseTime(severityChangedAt.String); ok {
+			i.SeverityChangedAt = t
+		}
+	}
+	if i.SeverityChangedAt.IsZero() {
+		i.SeverityChangedAt = i.StartedAt
+	}
+	if flapRecoveredAt.Valid {
+		if t, ok := parseTime(flapRecoveredAt.String); ok {
+			i.FlapRecoveredAt = &t
+		}
+	}
+	if createdAt.Valid {
+		if t, ok := parseTime(createdAt.String); ok {
+			i.CreatedAt = t
+		}
+	}
+	return &i, nil
 }
 
+// OpenIncident records a new incident in the investigating state.
 func (s *Store) OpenIncident(ctx context.Context, entityType, entityName, entityURL, severity, summary string) (int64, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
 	res, err := s.db.ExecContext(ctx,
-		`INSERT INTO incidents (entity_type, entity_name, entity_url, state, severity, summary, started_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		entityType, entityName, entityURL, IncidentStateOpen, severity, summary, now)
+		`INSERT INTO incidents (entity_type, entity_name, entity_url, state, severity, summary, started_at, state_changed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entityType, entityName, entityURL, IncidentStateInvestigating, severity, summary, now, now)
 	if err != nil {
 		return 0, err
 	}
 	return res.LastInsertId()
 }
 
+// SetIncidentSeverity changes the severity of an incident and records when.
+func (s *Store) SetIncidentSeverity(ctx context.Context, id int64, severity string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET severity = ?, severity_changed_at = ? WHERE id = ?`, severity, now, id)
+	return err
+}
+
+// SetIncidentFlap records the flap state of an incident. recoveredAt nil
+// means the entity is failing.
+func (s *Store) SetIncidentFlap(ctx context.Context, id int64, flapping bool, count int, recoveredAt *time.Time) error {
+	var rec interface{}
+	if recoveredAt != nil {
+		rec = recoveredAt.UTC().Format(time.RFC3339)
+	}
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET flapping = ?, flap_count = ?, flap_recovered_at = ? WHERE id = ?`,
+		flapping, count, rec, id)
+	return err
+}
+
+// SetIncidentMaintenance flags or unflags an incident as opened during maintenance.
+func (s *Store) SetIncidentMaintenance(ctx context.Context, id int64, maintenance bool) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET maintenance = ? WHERE id = ?`, maintenance, id)
+	return err
+}
+
+// SetIncidentManual marks an incident as opened by a responder.
+func (s *Store) SetIncidentManual(ctx context.Context, id int64, manual bool) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET manual = ? WHERE id = ?`, manual, id)
+	return err
+}
+
+// SetIncidentParent links an incident to its parent.
+func (s *Store) SetIncidentParent(ctx context.Context, id, parentID int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET parent_id = ? WHERE id = ?`, parentID, id)
+	return err
+}
+
+// SetIncidentImpactedBy records the upstream incident an incident is impacted
+// by; 0 clears it. Every upstream an incident was impacted by is kept for
+// ImpactedIncidents.
+func (s *Store) SetIncidentImpactedBy(ctx context.Context, id, upstreamID int64) error {
+	var v interface{}
+	if upstreamID != 0 {
+		v = upstreamID
+		if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO incident_impacts (upstream_id, incident_id) VALUES (?, ?)`,
+			upstreamID, id); err != nil {
+			return err
+		}
+	}
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET impacted_by = ? WHERE id = ?`, v, id)
+	return err
+}
+
+// ChildIncidents returns the incidents linked to a parent, oldest first.
+func (s *Store) ChildIncidents(ctx context.Context, parentID int64) ([]Incident, error) {
+	return s.queryIncidents(ctx, `SELECT `+incidentColumns+` FROM incidents WHERE parent_id = ? ORDER BY id`, parentID)
+}
+
+// ImpactedIncidents returns the incidents that were at any point marked
+// impacted by an upstream incident, oldest first, including those since
+// judged on their own or moved to another upstream.
+func (s *Store) ImpactedIncidents(ctx context.Context, upstreamID int64) ([]Incident, error) {
+	return s.queryIncidents(ctx, `SELECT `+incidentColumns+` FROM incidents
+		WHERE id IN (SELECT incident_id FROM incident_impacts WHERE upstream_id = ?) ORDER BY id`, upstreamID)
+}
+
+// OpenIncidentsOfType returns every unresolved incident of an entity type,
+// oldest first.
+func (s *Store) OpenIncidentsOfType(ctx context.Context, entityType string) ([]Incident, error) {
+	return s.queryIncidents(ctx, `SELECT `+incidentColumns+` FROM incidents WHERE state != ? AND entity_type = ? ORDER BY id`,
+		IncidentStateResolved, entityType)
+}
+
+// OpenIncidentsSince returns unresolved incidents that started at or after since.
+func (s *Store) OpenIncidentsSince(ctx context.Context, since time.Time) ([]Incident, error) {
+	return s.queryIncidents(ctx, `SELECT `+incidentColumns+` FROM incidents WHERE state != ? AND started_at >= ? ORDER BY id`,
+		IncidentStateResolved, since.UTC().Format(time.RFC3339))
+}
+
+// RecentIncidents returns unresolved incidents and those that ended at or
+// after endedAfter, newest first.
+func (s *Store) RecentIncidents(ctx context.Context, endedAfter time.Time) ([]Incident, error) {
+	return s.queryIncidents(ctx, `SELECT `+incidentColumns+` FROM incidents WHERE state != ? OR ended_at >= ? ORDER BY id DESC`,
+		IncidentStateResolved, endedAfter.UTC().Format(time.RFC3339))
+}
+
+// CountIncidentsSince counts incidents for an entity that started at or after since.
+func (s *Store) CountIncidentsSince(ctx context.Context, entityType, entityName string, since time.Time) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM incidents WHERE entity_type = ? AND entity_name = ? AND started_at >= ?`,
+		entityType, entityName, since.UTC().Format(time.RFC3339)).Scan(&n)
+	return n, err
+}
+
+// CloseIncident resolves the incident and replaces its summary.
 func (s *Store) CloseIncident(ctx context.Context, id int64, summary string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET state = ?, ended_at = ?, summary = ? WHERE id = ?`, IncidentStateClosed, now, summary, id)
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET state = ?, ended_at = ?, state_changed_at = ?, summary = ? WHERE id = ?`,
+		IncidentStateResolved, now, now, summary, id)
+	return err
+}
+
+// SetIncidentState moves the incident to state; resolving also sets ended_at.
+func (s *Store) SetIncidentState(ctx context.Context, id int64, state string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var endedAt sql.NullString
+	if state == IncidentStateResolved {
+		endedAt = sql.NullString{String: now, Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET state = ?, state_changed_at = ?, ended_at = ? WHERE id = ?`,
+		state, now, endedAt, id)
+	return err
+}
+
+func (s *Store) AcknowledgeIncident(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE incidents SET acknowledged = 1 WHERE id = ?`, id)
 	return err
 }
 
 func (s *Store) HasOpenIncident(ctx context.Context, entityType, entityName string) (bool, int64, error) {
 	var id int64
 	err := s.db.QueryRowContext(ctx,
-		`SELECT id FROM incidents WHERE entity_type = ? AND entity_name = ? AND state = ?`,
-		entityType, entityName, IncidentStateOpen).Scan(&id)
+		`SELECT id FROM incidents WHERE entity_type = ? AND entity_name = ? AND state != ? ORDER BY id DESC LIMIT 1`,
+		entityType, entityName, IncidentStateResolved).Scan(&id)
 	if err == sql.ErrNoRows {
 		return false, 0, nil
 	}
@@ -65,47 +290,35 @@ func (s *Store) HasOpenIncident(ctx context.Context, entityType, entityName stri
 }
 
 func (s *Store) GetIncident(ctx context.Context, id int64) (*Incident, error) {
-	var i Incident
-	var startedAt, endedAt, createdAt sql.NullString
-	err := s.db.QueryRowContext(ctx,
-		`SELECT id, entity_type, entity_name, entity_url, state, severity, summary, started_at, ended_at, created_at
-		 FROM incidents WHERE id = ?`, id).Scan(
-		&i.ID, &i.EntityType, &i.EntityName, &i.EntityURL, &i.State, &i.Severity, &i.Summary,
-		&startedAt, &endedAt, &createdAt)
-	if err != nil {
-		return nil, err
-	}
-	if startedAt.Valid {
-		if t, ok := parseTime(startedAt.String); ok {
-			i.StartedAt = t
-		}
-	}
-	if endedAt.Valid {
-		if t, ok := parseTime(endedAt.String); ok {
-			i.EndedAt = &t
-		}
-	}
-	if createdAt.Valid {
-		if t, ok := parseTime(createdAt.String); ok {
-			i.CreatedAt = t
-		}
-	}
-	return &i, nil
+	return scanIncident(s.db.QueryRowContext(ctx, `SELECT `+incidentColumns+` FROM incidents WHERE id = ?`, id))
 }
 
+// ListIncidents filters by a lifecycle state or by IncidentStateOpen /
+// IncidentStateClosed; an empty state lists everything.
 func (s *Store) ListIncidents(ctx context.Context, state string, limit int) ([]Incident, error) {
 	if limit <= 0 {
 		limit = 50
 	}
-	query := `SELECT id, entity_type, entity_name, entity_url, state, severity, summary, started_at, ended_at, created_at FROM incidents`
+	query := `SELECT ` + incidentColumns + ` FROM incidents`
 	args := []interface{}{}
-	if state != "" {
+	switch state {
+	case "":
+	case IncidentStateOpen:
+		query += ` WHERE state != ?`
+		args = append(args, IncidentStateResolved)
+	case IncidentStateClosed:
+		query += ` WHERE state = ?`
+		args = append(args, IncidentStateResolved)
+	default:
 		query += ` WHERE state = ?`
 		args = append(args, state)
 	}
 	query += ` ORDER BY started_at DESC LIMIT ?`
 	args = append(args, limit)
+	return s.queryIncidents(ctx, query, args...)
+}
 
+func (s *Store) queryIncidents(ctx context.Context, query string, args ...interface{}) ([]Incident, error) {
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -113,41 +326,36 @@ func (s *Store) ListIncidents(ctx context.Context, state string, limit int) ([]I
 	defer func() { _ = rows.Close() }()
 	var out []Incident
 	for rows.Next() {
-		var i Incident
-		var startedAt, endedAt, createdAt sql.NullString
-		err := rows.Scan(&i.ID, &i.EntityType, &i.EntityName, &i.EntityURL, &i.State, &i.Severity, &i.Summary,
-			&startedAt, &endedAt, &createdAt)
+		i, err := scanIncident(rows)
 		if err != nil {
 			return nil, err
 		}
-		if startedAt.Valid {
-			if t, ok := parseTime(startedAt.String); ok {
-				i.StartedAt = t
-			}
-		}
-		if endedAt.Valid {
-			if t, ok := parseTime(endedAt.String); ok {
-				i.EndedAt = &t
-			}
-		}
-		if createdAt.Valid {
-			if t, ok := parseTime(createdAt.String); ok {
-				i.CreatedAt = t
-			}
-		}
-		out = append(out, i)
+		out = append(out, *i)
 	}
 	return out, rows.Err()
 }
 
+// AddIncidentUpdate appends message to the incident's timeline, tagged with
+// the incident's current state.
 func (s *Store) AddIncidentUpdate(ctx context.Context, incidentID int64, message string) error {
-	_, err := s.db.ExecContext(ctx, `INSERT INTO incident_updates (incident_id, message) VALUES (?, ?)`, incidentID, message)
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO incident_updates (incident_id, state, message) VALUES (?, (SELECT state FROM incidents WHERE id = ?), ?)`,
+		incidentID, incidentID, message)
+	return err
+}
+
+// AddSeverityUpdate records a severity change in the timeline.
+func (s *Store) AddSeverityUpdate(ctx context.Context, incidentID int64, from, to, message string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO incident_updates (incident_id, state, from_severity, to_severity, message)
+		 VALUES (?, (SELECT state FROM incidents WHERE id = ?), ?, ?, ?)`,
+		incidentID, incidentID, from, to, message)
 	return err
 }
 
 func (s *Store) IncidentUpdates(ctx context.Context, incidentID int64) ([]IncidentUpdate, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, incident_id, message, created_at FROM incident_updates WHERE incident_id = ? ORDER BY created_at ASC`,
+		`SELECT id, incident_id, state, from_severity, to_severity, message, created_at FROM incident_updates WHERE incident_id = ? ORDER BY created_at ASC, id ASC`,
 		incidentID)
 	if err != nil {
 		return nil, err
@@ -156,10 +364,13 @@ func (s *Store) IncidentUpdates(ctx context.Context, incidentID int64) ([]Incide
 	var out []IncidentUpdate
 	for rows.Next() {
 		var u IncidentUpdate
+		var state, from, to sql.NullString
 		var createdAt string
-		if err := rows.Scan(&u.ID, &u.IncidentID, &u.Message, &createdAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.IncidentID, &state, &from, &to, &u.Message, &createdAt); err != nil {
 			return nil, err
 		}
+		u.State = state.String
+		u.FromSeverity, u.ToSeverity = from.String, to.String
 		if t, ok := parseTime(createdAt); ok {
 			u.CreatedAt = t
 		}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Maintenance is a scheduled window during which incidents for matching
+// entities are suppressed or flagged. Exactly one of Entity, Tag or All is set.
+type Maintenance struct {
+	ID        int64
+	Entity    string
+	Tag       string
+	All       bool
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Reason    string
+	Mode      string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// Active reports whether the window covers t.
+func (m *Maintenance) Active(t time.Time) bool {
+	return !t.Before(m.StartsAt) && t.Before(m.EndsAt)
+}
+
+func (s *Store) InsertMaintenance(ctx context.Context, m *Maintenance) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO maintenance_windows (entity, tag, all_entities, starts_at, ends_at, reason, mode, created_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullString(m.Entity), nullString(m.Tag), m.All, m.StartsAt.UTC().Format(time.RFC3339), m.EndsAt.UTC().Format(time.RFC3339),
+		nullString(m.Reason), m.Mode, nullString(m.CreatedBy))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ListMaintenance returns windows that end after t, earliest start first.
+func (s *Store) ListMaintenance(ctx context.Context, t time.Time) ([]Maintenance, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, entity, tag, all_entities, starts_at, ends_at, reason, mode, created_by, created_at
+		 FROM maintenance_windows WHERE ends_at > ? ORDER BY starts_at, id`, t.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []Maintenance
+	for rows.Next() {
+		var m Maintenance
+		var entity, tag, reason, createdBy sql.NullString
+		var all int64
+		var startsAt, endsAt, createdAt string
+		if err := rows.Scan(&m.ID, &entity, &tag, &all, &startsAt, &endsAt, &reason, &m.Mode, &createdBy, &createdAt); err != nil {
+			return nil, err
+		}
+		m.Entity, m.Tag, m.Reason, m.CreatedBy = entity.String, tag.String, reason.String, createdBy.String
+		m.All = all != 0
+		m.StartsAt, _ = parseTime(startsAt)
+		m.EndsAt, _ = parseTime(endsAt)
+		m.CreatedAt, _ = parseTime(createdAt)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// DeleteMaintenance removes a window and reports whether it existed.
+func (s *Store) DeleteMaintenance(ctx context.Context, id int64) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM maintenance_windows WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
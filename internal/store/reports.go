@@ -2,7 +2,10 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"strings"
 	"time"
 )
 
@@ -16,8 +19,11 @@ type Report struct {
 	URL         string
 	TxHash      string
 	UserAgent   string
-	IncidentID  sql.NullInt64
-	CreatedAt   time.Time
+	// Reporter is an opaque key for whoever sent the report (see
+	// ReporterKey), used to count distinct reporters; empty when unknown.
+	Reporter   string
+	IncidentID sql.NullInt64
+	CreatedAt  time.Time
 }
 
 const (
@@ -31,14 +37,19 @@ const (
 	MaxReportUserAgent   = 512
 )
 
+// InsertReport stores a report. A zero CreatedAt means now.
 func (s *Store) InsertReport(ctx context.Context, r *Report) (int64, error) {
 	var incidentID sql.NullInt64
 	if r.IncidentID.Valid {
 		incidentID = r.IncidentID
 	}
+	createdAt := r.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
 	res, err := s.db.ExecContext(ctx,
-		`INSERT INTO reports (issue_type, wallet, device, region, description, url, tx_hash, user_agent, incident_id)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO reports (issue_type, wallet, device, region, description, url, tx_hash, user_agent, reporter, incident_id, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		trunc(r.IssueType, MaxReportIssueType),
 		trunc(r.Wallet, MaxReportWallet),
 		trunc(r.Device, MaxReportDevice),
@@ -47,7 +58,9 @@ func (s *Store) InsertReport(ctx context.Context, r *Report) (int64, error) {
 		trunc(r.URL, MaxReportURL),
 		trunc(r.TxHash, MaxReportTxHash),
 		trunc(r.UserAgent, MaxReportUserAgent),
-		incidentID)
+		nullString(r.Reporter),
+		incidentID,
+		createdAt.UTC().Format(sqlTime))
 	if err != nil {
 		return 0, err
 	}
@@ -59,9 +72,37 @@ func (s *Store) ListReports(ctx context.Context, limit int) ([]Report, error) {
 	if limit <= 0 {
 		limit = 50
 	}
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, issue_type, wallet, device, region, description, url, tx_hash, user_agent, incident_id, created_at
-		 FROM reports ORDER BY created_at DESC LIMIT ?`, limit)
+	return s.queryReports(ctx, `SELECT `+reportColumns+` FROM reports ORDER BY created_at DESC LIMIT ?`, limit)
+}
+
+// ReportsSince returns reports created at or after since, oldest first.
+func (s *Store) ReportsSince(ctx context.Context, since time.Time) ([]Report, error) {
+	return s.queryReports(ctx, `SELECT `+reportColumns+` FROM reports WHERE created_at >= ? ORDER BY created_at, id`,
+		since.UTC().Format(sqlTime))
+}
+
+// IncidentReports returns the reports linked to an incident or to its child
+// incidents, oldest first.
+func (s *Store) IncidentReports(ctx context.Context, incidentID int64) ([]Report, error) {
+	return s.queryReports(ctx, `SELECT `+reportColumns+` FROM reports
+		WHERE incident_id = ? OR incident_id IN (SELECT id FROM incidents WHERE parent_id = ?) ORDER BY created_at, id`,
+		incidentID, incidentID)
+}
+
+// LinkReports links reports to an incident.
+func (s *Store) LinkReports(ctx context.Context, incidentID int64, ids []int64) error {
+	for _, id := range ids {
+		if _, err := s.db.ExecContext(ctx, `UPDATE reports SET incident_id = ? WHERE id = ?`, incidentID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const reportColumns = `id, issue_type, wallet, device, region, description, url, tx_hash, user_agent, reporter, incident_id, created_at`
+
+func (s *Store) queryReports(ctx context.Context, query string, args ...interface{}) ([]Report, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -69,10 +110,12 @@ func (s *Store) ListReports(ctx context.Context, limit int) ([]Report, error) {
 	var out []Report
 	for rows.Next() {
 		var r Report
+		var reporter sql.NullString
 		var createdAt string
-		if err := rows.Scan(&r.ID, &r.IssueType, &r.Wallet, &r.Device, &r.Region, &r.Description, &r.URL, &r.TxHash, &r.UserAgent, &r.IncidentID, &createdAt); err != nil {
+		if err := rows.Scan(&r.ID, &r.IssueType, &r.Wallet, &r.Device, &r.Region, &r.Description, &r.URL, &r.TxHash, &r.UserAgent, &reporter, &r.IncidentID, &createdAt); err != nil {
 			return nil, err
 		}
+		r.Reporter = reporter.String
 		if t, ok := parseTime(createdAt); ok {
 			r.CreatedAt = t
 		}
@@ -81,6 +124,14 @@ func (s *Store) ListReports(ctx context.Context, limit int) ([]Report, error) {
 	return out, rows.Err()
 }
 
+// ReporterKey derives a report's Reporter from identifying parts, such as a
+// client address and user agent, without storing them: source plus a short
+// hash, e.g. "api:3f2a…".
+func ReporterKey(source string, parts ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return source + ":" + hex.EncodeToString(h[:8])
+}
+
 func trunc(s string, max int) string {
 	if len(s) <= max {
 		return s
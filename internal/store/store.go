@@ -55,16 +55,38 @@ func (s *Store) migrate(ctx context.Context) error {
 			created_at TEXT NOT NULL DEFAULT (datetime('now'))
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_checks_entity_created ON checks(entity_type, entity_name, created_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS check_steps (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			check_id INTEGER NOT NULL REFERENCES checks(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			success INTEGER NOT NULL,
+			status INTEGER,
+			latency_ms INTEGER NOT NULL,
+			error_category TEXT,
+			detail TEXT
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_check_steps_check ON check_steps(check_id)`,
 		`CREATE TABLE IF NOT EXISTS incidents (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			entity_type TEXT NOT NULL,
 			entity_name TEXT NOT NULL,
 			entity_url TEXT,
 			state TEXT NOT NULL,
+			acknowledged INTEGER NOT NULL DEFAULT 0,
 			severity TEXT NOT NULL,
 			summary TEXT NOT NULL,
 			started_at TEXT NOT NULL,
 			ended_at TEXT,
+			state_changed_at TEXT,
+			severity_changed_at TEXT,
+			flapping INTEGER NOT NULL DEFAULT 0,
+			flap_count INTEGER NOT NULL DEFAULT 0,
+			flap_recovered_at TEXT,
+			maintenance INTEGER NOT NULL DEFAULT 0,
+			parent_id INTEGER REFERENCES incidents(id),
+			impacted_by INTEGER REFERENCES incidents(id),
+			manual INTEGER NOT NULL DEFAULT 0,
 			created_at TEXT NOT NULL DEFAULT (datetime('now'))
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_incidents_state ON incidents(state)`,
@@ -72,9 +94,30 @@ func (s *Store) migrate(ctx context.Context) error {
 		`CREATE TABLE IF NOT EXISTS incident_updates (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			incident_id INTEGER NOT NULL REFERENCES incidents(id),
+			state TEXT,
+			from_severity TEXT,
+			to_severity TEXT,
 			message TEXT NOT NULL,
 			created_at TEXT NOT NULL DEFAULT (datetime('now'))
 		)`,
+		`CREATE TABLE IF NOT EXISTS incident_impacts (
+			upstream_id INTEGER NOT NULL REFERENCES incidents(id),
+			incident_id INTEGER NOT NULL REFERENCES incidents(id),
+			created_at TEXT NOT NULL DEFAULT (datetime('now')),
+			PRIMARY KEY (upstream_id, incident_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS maintenance_windows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity TEXT,
+			tag TEXT,
+			all_entities INTEGER NOT NULL DEFAULT 0,
+			starts_at TEXT NOT NULL,
+			ends_at TEXT NOT NULL,
+			reason TEXT,
+			mode TEXT NOT NULL,
+			created_by TEXT,
+			created_at TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
 		`CREATE TABLE IF NOT EXISTS reports (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			issue_type TEXT NOT NULL,
@@ -85,18 +128,100 @@ func (s *Store) migrate(ctx context.Context) error {
 			url TEXT,
 			tx_hash TEXT,
 			user_agent TEXT,
+			reporter TEXT,
 			incident_id INTEGER REFERENCES incidents(id),
 			created_at TEXT NOT NULL DEFAULT (datetime('now'))
 		)`,
+		`CREATE INDEX IF NOT EXISTS idx_reports_incident ON reports(incident_id)`,
+		`CREATE TABLE IF NOT EXISTS uptime_hourly (
+			entity_type TEXT NOT NULL,
+			entity_name TEXT NOT NULL,
+			bucket TEXT NOT NULL,
+			success INTEGER NOT NULL,
+			total INTEGER NOT NULL,
+			p50_ms INTEGER NOT NULL DEFAULT 0,
+			p95_ms INTEGER NOT NULL DEFAULT 0,
+			max_ms INTEGER NOT NULL DEFAULT 0,
+			errors TEXT NOT NULL DEFAULT '{}',
+			PRIMARY KEY (entity_type, entity_name, bucket)
+		)`,
+		`CREATE TABLE IF NOT EXISTS uptime_daily (
+			entity_type TEXT NOT NULL,
+			entity_name TEXT NOT NULL,
+			bucket TEXT NOT NULL,
+			success INTEGER NOT NULL,
+			total INTEGER NOT NULL,
+			p50_ms INTEGER NOT NULL DEFAULT 0,
+			p95_ms INTEGER NOT NULL DEFAULT 0,
+			max_ms INTEGER NOT NULL DEFAULT 0,
+			errors TEXT NOT NULL DEFAULT '{}',
+			PRIMARY KEY (entity_type, entity_name, bucket)
+		)`,
 	}
 	for _, q := range queries {
 		if _, err := s.db.ExecContext(ctx, q); err != nil {
 			return fmt.Errorf("migrate %s: %w", q[:40], err)
 		}
 	}
+	// Columns added after the first release; CREATE TABLE IF NOT EXISTS
+	// leaves older databases without them.
+	columns := []struct{ table, column, def string }{
+		{"incidents", "acknowledged", "INTEGER NOT NULL DEFAULT 0"},
+		{"incidents", "state_changed_at", "TEXT"},
+		{"incidents", "severity_changed_at", "TEXT"},
+		{"incidents", "flapping", "INTEGER NOT NULL DEFAULT 0"},
+		{"incidents", "flap_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"incidents", "flap_recovered_at", "TEXT"},
+		{"incidents", "maintenance", "INTEGER NOT NULL DEFAULT 0"},
+		{"incidents", "parent_id", "INTEGER REFERENCES incidents(id)"},
+		{"incidents", "impacted_by", "INTEGER REFERENCES incidents(id)"},
+		{"incidents", "manual", "INTEGER NOT NULL DEFAULT 0"},
+		{"incident_updates", "state", "TEXT"},
+		{"incident_updates", "from_severity", "TEXT"},
+		{"incident_updates", "to_severity", "TEXT"},
+		{"reports", "reporter", "TEXT"},
+	}
+	for _, c := range columns {
+		if err := s.ensureColumn(ctx, c.table, c.column, c.def); err != nil {
+			return fmt.Errorf("migrate %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	// Incidents used to be only open or closed.
+	updates := []string{
+		`UPDATE incidents SET state = 'investigating' WHERE state = 'open'`,
+		`UPDATE incidents SET state = 'resolved' WHERE state = 'closed'`,
+	}
+	for _, q := range updates {
+		if _, err := s.db.ExecContext(ctx, q); err != nil {
+			return fmt.Errorf("migrate %s: %w", q[:40], err)
+		}
+	}
 	return nil
 }
 
+func (s *Store) ensureColumn(ctx context.Context, table, column, def string) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_ = rows.Close()
+	_, err = s.db.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+column+` `+def)
+	return err
+}
+
 func (s *Store) Close() error {
 	return s.db.Close()
 }
@@ -2,8 +2,10 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNew_and_Migrate(t *testing.T) {
@@ -127,7 +129,7 @@ func TestIncidents(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetIncident: %v", err)
 	}
-	if inc.State != IncidentStateOpen || inc.Severity != "CRIT" {
+	if inc.State != IncidentStateInvestigating || inc.Severity != "CRIT" || !inc.Open() {
 		t.Errorf("incident state=%q severity=%q", inc.State, inc.Severity)
 	}
 
@@ -141,13 +143,16 @@ func TestIncidents(t *testing.T) {
 	if len(updates) != 1 {
 		t.Fatalf("len(updates) = %d", len(updates))
 	}
+	if updates[0].State != IncidentStateInvestigating {
+		t.Errorf("update state = %q", updates[0].State)
+	}
 
 	if err := s.CloseIncident(ctx, incID, "Resolved"); err != nil {
 		t.Fatalf("CloseIncident: %v", err)
 	}
 	inc, _ = s.GetIncident(ctx, incID)
-	if inc.State != IncidentStateClosed {
-		t.Errorf("state after close = %q", inc.State)
+	if inc.State != IncidentStateResolved || inc.EndedAt == nil {
+		t.Errorf("state after close = %q, ended_at = %v", inc.State, inc.EndedAt)
 	}
 
 	ok, _, _ = s.HasOpenIncident(ctx, "rpc", "aptoslabs")
@@ -164,6 +169,89 @@ func TestIncidents(t *testing.T) {
 	}
 }
 
+func TestIncidentLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	s, err := New(ctx, filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	id, _ := s.OpenIncident(ctx, "dapp", "explorer", "https://explorer.aptoslabs.com", "CRIT", "Endpoint unreachable")
+	for _, state := range []string{IncidentStateIdentified, IncidentStateMonitoring} {
+		if err := s.SetIncidentState(ctx, id, state); err != nil {
+			t.Fatalf("SetIncidentState(%s): %v", state, err)
+		}
+		_ = s.AddIncidentUpdate(ctx, id, "moved to "+state)
+	}
+	if err := s.AcknowledgeIncident(ctx, id); err != nil {
+		t.Fatalf("AcknowledgeIncident: %v", err)
+	}
+	inc, _ := s.GetIncident(ctx, id)
+	if inc.State != IncidentStateMonitoring || !inc.Acknowledged || inc.EndedAt != nil {
+		t.Errorf("incident = %+v", inc)
+	}
+	if ok, _, _ := s.HasOpenIncident(ctx, "dapp", "explorer"); !ok {
+		t.Error("monitoring incident should count as open")
+	}
+	open, _ := s.ListIncidents(ctx, IncidentStateOpen, 10)
+	monitoring, _ := s.ListIncidents(ctx, IncidentStateMonitoring, 10)
+	if len(open) != 1 || len(monitoring) != 1 {
+		t.Errorf("ListIncidents: open=%d monitoring=%d", len(open), len(monitoring))
+	}
+	updates, _ := s.IncidentUpdates(ctx, id)
+	if len(updates) != 2 || updates[1].State != IncidentStateMonitoring {
+		t.Errorf("updates = %+v", updates)
+	}
+	if err := s.SetIncidentState(ctx, id, IncidentStateResolved); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	inc, _ = s.GetIncident(ctx, id)
+	if inc.Open() || inc.EndedAt == nil {
+		t.Errorf("resolved incident = %+v", inc)
+	}
+}
+
+func TestMigrate_LegacyIncidentStates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, q := range []string{
+		`CREATE TABLE incidents (id INTEGER PRIMARY KEY AUTOINCREMENT, entity_type TEXT NOT NULL, entity_name TEXT NOT NULL,
+			entity_url TEXT, state TEXT NOT NULL, severity TEXT NOT NULL, summary TEXT NOT NULL, started_at TEXT NOT NULL,
+			ended_at TEXT, created_at TEXT NOT NULL DEFAULT (datetime('now')))`,
+		`CREATE TABLE incident_updates (id INTEGER PRIMARY KEY AUTOINCREMENT, incident_id INTEGER NOT NULL REFERENCES incidents(id),
+			message TEXT NOT NULL, created_at TEXT NOT NULL DEFAULT (datetime('now')))`,
+		`INSERT INTO incidents (entity_type, entity_name, state, severity, summary, started_at) VALUES ('rpc', 'a', 'open', 'CRIT', 'down', '2025-01-01T00:00:00Z')`,
+		`INSERT INTO incidents (entity_type, entity_name, state, severity, summary, started_at) VALUES ('rpc', 'b', 'closed', 'CRIT', 'ok', '2025-01-01T00:00:00Z')`,
+	} {
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			t.Fatalf("legacy schema: %v", err)
+		}
+	}
+	_ = db.Close()
+
+	s, err := New(ctx, path)
+	if err != nil {
+		t.Fatalf("New on legacy db: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	a, err := s.GetIncident(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetIncident: %v", err)
+	}
+	b, _ := s.GetIncident(ctx, 2)
+	if a.State != IncidentStateInvestigating || b.State != IncidentStateResolved {
+		t.Errorf("states = %q, %q", a.State, b.State)
+	}
+	if err := s.AddIncidentUpdate(ctx, 1, "still down"); err != nil {
+		t.Fatalf("AddIncidentUpdate on migrated db: %v", err)
+	}
+}
+
 func TestReports(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.db")
@@ -194,6 +282,71 @@ func TestReports(t *testing.T) {
 	}
 }
 
+func TestIncidentReportCount(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	parentID, _ := s.OpenIncident(ctx, "ecosystem", "network", "", SeverityCrit, "network-wide")
+	childID, _ := s.OpenIncident(ctx, "rpc", "a", "", SeverityCrit, "down")
+	_ = s.SetIncidentParent(ctx, childID, parentID)
+	for _, id := range []int64{childID, childID, parentID} {
+		if _, err := s.InsertReport(ctx, &Report{IssueType: "rpc_down", IncidentID: sql.NullInt64{Int64: id, Valid: true}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _ = s.InsertReport(ctx, &Report{IssueType: "other"})
+	child, _ := s.GetIncident(ctx, childID)
+	parent, _ := s.GetIncident(ctx, parentID)
+	if child.ReportCount != 2 || parent.ReportCount != 3 {
+		t.Errorf("report counts: child %d, parent %d", child.ReportCount, parent.ReportCount)
+	}
+
+	_ = s.CloseIncident(ctx, childID, "ok")
+	recent, err := s.RecentIncidents(ctx, time.Now().Add(-time.Minute))
+	if err != nil || len(recent) != 2 {
+		t.Fatalf("RecentIncidents = %d, %v", len(recent), err)
+	}
+	if recent, _ = s.RecentIncidents(ctx, time.Now().Add(time.Hour)); len(recent) != 1 || recent[0].ID != parentID {
+		t.Errorf("RecentIncidents after the close window = %+v", recent)
+	}
+}
+
+func TestImpactedIncidents(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	upstream, _ := s.OpenIncident(ctx, "rpc", "a", "", SeverityCrit, "down")
+	next, _ := s.OpenIncident(ctx, "rpc", "e", "", SeverityCrit, "down")
+	still, _ := s.OpenIncident(ctx, "dapp", "b", "", SeverityWarn, "down")
+	moved, _ := s.OpenIncident(ctx, "dapp", "c", "", SeverityWarn, "down")
+	other, _ := s.OpenIncident(ctx, "dapp", "d", "", SeverityWarn, "down")
+	// still kept failing after the upstream resolved; moved was re-pointed
+	// to the next upstream. Both stay impacted by the first.
+	_ = s.SetIncidentImpactedBy(ctx, still, upstream)
+	_ = s.SetIncidentImpactedBy(ctx, still, 0)
+	_ = s.SetIncidentImpactedBy(ctx, moved, upstream)
+	_ = s.SetIncidentImpactedBy(ctx, moved, next)
+	_ = s.SetIncidentImpactedBy(ctx, other, next)
+	list, err := s.ImpactedIncidents(ctx, upstream)
+	if err != nil {
+		t.Fatalf("ImpactedIncidents: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != still || list[1].ID != moved {
+		t.Errorf("impacted = %+v", list)
+	}
+	if list, _ := s.ImpactedIncidents(ctx, next); len(list) != 2 || list[0].ID != moved || list[1].ID != other {
+		t.Errorf("impacted by next = %+v", list)
+	}
+}
+
 func int64Ptr(n int64) *int64 { return &n }
 
 func TestTrimChecks(t *testing.T) {
@@ -216,3 +369,141 @@ func TestTrimChecks(t *testing.T) {
 		t.Errorf("after trim len = %d", len(list))
 	}
 }
+
+func TestCheckSteps(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.db")
+	ctx := context.Background()
+	s, err := New(ctx, path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	steps := []CheckStep{
+		{Name: "quote", Success: true, Status: 200, LatencyMs: 40},
+		{Name: "build", Status: 500, LatencyMs: 12, ErrorCategory: "http_5xx"},
+	}
+	if err := s.InsertCheckWithSteps(ctx, "dapp", "dex", false, nil, "http_5xx", steps); err != nil {
+		t.Fatalf("InsertCheckWithSteps: %v", err)
+	}
+	checks, _ := s.RecentChecks(ctx, "dapp", "dex", 1)
+	if len(checks) != 1 {
+		t.Fatalf("len(checks) = %d", len(checks))
+	}
+	got, err := s.CheckSteps(ctx, checks[0].ID)
+	if err != nil {
+		t.Fatalf("CheckSteps: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "quote" || got[1].ErrorCategory != "http_5xx" || got[1].Status != 500 {
+		t.Errorf("steps = %+v", got)
+	}
+	_ = s.InsertCheck(ctx, "dapp", "dex", true, int64Ptr(30), "")
+	if err := s.TrimChecks(ctx, "dapp", "dex", 1); err != nil {
+		t.Fatalf("TrimChecks with steps: %v", err)
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	ctx := context.Background()
+	st, err := New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	now := time.Now().UTC().Truncate(time.Second)
+	past := &Maintenance{All: true, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour), Mode: "suppress"}
+	next := &Maintenance{Tag: "tier=premium", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour), Mode: "maintenance", CreatedBy: "ops"}
+	cur := &Maintenance{Entity: "rpc/a", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), Mode: "suppress", Reason: "Upgrade"}
+	for _, m := range []*Maintenance{past, next, cur} {
+		if _, err := st.InsertMaintenance(ctx, m); err != nil {
+			t.Fatalf("InsertMaintenance: %v", err)
+		}
+	}
+	list, err := st.ListMaintenance(ctx, now)
+	if err != nil {
+		t.Fatalf("ListMaintenance: %v", err)
+	}
+	if len(list) != 2 || list[0].Entity != "rpc/a" || list[1].Tag != "tier=premium" || list[1].CreatedBy != "ops" {
+		t.Fatalf("list = %+v", list)
+	}
+	if !list[0].Active(now) || list[1].Active(now) || !list[0].EndsAt.Equal(cur.EndsAt) {
+		t.Errorf("active/ends = %v %v %v", list[0].Active(now), list[1].Active(now), list[0].EndsAt)
+	}
+	if ok, err := st.DeleteMaintenance(ctx, list[0].ID); err != nil || !ok {
+		t.Errorf("DeleteMaintenance: %v %v", ok, err)
+	}
+	if ok, _ := st.DeleteMaintenance(ctx, list[0].ID); ok {
+		t.Error("second delete reported a row")
+	}
+}
+
+func TestRollupUptime(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	insert := func(at string, success bool, lat *int64, errCat string) {
+		t.Helper()
+		if _, err := s.db.ExecContext(ctx,
+			`INSERT INTO checks (entity_type, entity_name, success, latency_ms, error_category, created_at) VALUES ('rpc', 'x', ?, ?, ?, ?)`,
+			success, lat, errCat, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 10; i++ {
+		insert("2026-03-01 10:05:00", true, int64Ptr(int64(10*i)), "")
+	}
+	insert("2026-03-01 10:30:00", false, nil, "timeout")
+	insert("2026-03-01 11:00:00", true, int64Ptr(500), "")
+	if err := s.RollupUptime(ctx); err != nil {
+		t.Fatalf("RollupUptime: %v", err)
+	}
+	since, _ := time.Parse(time.RFC3339, "2026-03-01T00:00:00Z")
+	hours, err := s.UptimeBuckets(ctx, "rpc", "x", UptimeHourly, since)
+	if err != nil {
+		t.Fatalf("UptimeBuckets: %v", err)
+	}
+	if len(hours) != 2 {
+		t.Fatalf("hourly buckets = %d", len(hours))
+	}
+	h := hours[0]
+	if h.Start.Hour() != 10 || h.Success != 10 || h.Total != 11 || h.Errors["timeout"] != 1 {
+		t.Errorf("10:00 bucket = %+v", h)
+	}
+	if h.P50Ms != 50 || h.P95Ms != 100 || h.MaxMs != 100 {
+		t.Errorf("latency = p50 %d, p95 %d, max %d", h.P50Ms, h.P95Ms, h.MaxMs)
+	}
+
+	days, _ := s.UptimeBuckets(ctx, "rpc", "x", UptimeDaily, since)
+	if len(days) != 1 || days[0].Total != 12 || days[0].Success != 11 || days[0].MaxMs != 500 {
+		t.Errorf("daily = %+v", days)
+	}
+
+	// A later run recomputes the newest bucket and keeps older ones even
+	// once their checks are gone.
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM checks WHERE created_at < '2026-03-01 11:00:00'`); err != nil {
+		t.Fatal(err)
+	}
+	insert("2026-03-01 11:10:00", false, nil, "")
+	if err := s.RollupUptime(ctx); err != nil {
+		t.Fatalf("RollupUptime: %v", err)
+	}
+	hours, _ = s.UptimeBuckets(ctx, "rpc", "x", UptimeHourly, since)
+	if len(hours) != 2 || hours[0].Total != 11 || hours[1].Total != 2 || hours[1].Errors["unknown"] != 1 {
+		t.Errorf("hourly after second run = %+v", hours)
+	}
+	// Each entity resumes from its own newest bucket: y's checks from before
+	// x's 11:00 bucket are still rolled up.
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO checks (entity_type, entity_name, success, latency_ms, created_at) VALUES ('rpc', 'y', 1, 40, '2026-03-01 09:15:00')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RollupUptime(ctx); err != nil {
+		t.Fatalf("RollupUptime: %v", err)
+	}
+	if hours, _ := s.UptimeBuckets(ctx, "rpc", "y", UptimeHourly, since); len(hours) != 1 || hours[0].Start.Hour() != 9 || hours[0].P95Ms != 40 {
+		t.Errorf("hourly for y = %+v", hours)
+	}
+}
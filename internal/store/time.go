@@ -2,8 +2,12 @@ package store
 
 import "time"
 
+// sqlTime matches the format of the created_at column defaults, so times
+// compared against those columns must be formatted with it, in UTC.
+const sqlTime = "2006-01-02 15:04:05"
+
 func parseTime(s string) (time.Time, bool) {
-	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05Z"} {
+	for _, layout := range []string{time.RFC3339, sqlTime, "2006-01-02T15:04:05Z"} {
 		if t, err := time.Parse(layout, s); err == nil {
 			return t, true
 		}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gorusys/aptos-guardian/internal/util/stats"
+)
+
+// Uptime rollup granularities.
+const (
+	UptimeHourly = "hourly"
+	UptimeDaily  = "daily"
+)
+
+// uptimeBuckets maps a granularity to the strftime format that truncates a
+// check's created_at to the start of its bucket.
+var uptimeBuckets = map[string]string{
+	UptimeHourly: "%Y-%m-%d %H:00:00",
+	UptimeDaily:  "%Y-%m-%d 00:00:00",
+}
+
+// UptimeBucket summarises one entity's checks over an hour or a day.
+type UptimeBucket struct {
+	EntityType string
+	EntityName string
+	Start      time.Time
+	Success    int
+	Total      int
+	// P50Ms, P95Ms and MaxMs cover successful checks; zero without any.
+	P50Ms, P95Ms, MaxMs int64
+	// Errors counts failed checks by error category.
+	Errors map[string]int
+}
+
+// RollupUptime folds checks into the hourly and daily uptime tables. Each
+// run recomputes an entity's buckets from its newest stored one onwards, so
+// the bucket in progress stays current while older buckets keep their totals
+// after the raw checks are trimmed. The day in progress is recomputed from
+// its checks, so they must be kept for at least a day.
+func (s *Store) RollupUptime(ctx context.Context) error {
+	for _, g := range []string{UptimeHourly, UptimeDaily} {
+		if err := s.rollupUptime(ctx, g); err != nil {
+			return fmt.Errorf("rollup %s uptime: %w", g, err)
+		}
+	}
+	return nil
+}
+
+// pendingChecks selects, as c, the checks of each entity from the start of
+// its newest rollup bucket onwards, or all of them for an entity without
+// one. %s is the rollup table.
+const pendingChecks = `checks c LEFT JOIN (
+		SELECT entity_type, entity_name, MAX(bucket) AS bucket FROM %s GROUP BY entity_type, entity_name
+	) u ON u.entity_type = c.entity_type AND u.entity_name = c.entity_name
+	WHERE (u.bucket IS NULL OR c.created_at >= u.bucket)`
+
+// rollupUptime counts checks per bucket in SQL and streams the successful
+// latencies in order, so only one bucket's latencies are held at a time.
+func (s *Store) rollupUptime(ctx context.Context, granularity string) error {
+	table := "uptime_" + granularity
+	from := fmt.Sprintf(pendingChecks, table)
+	format := uptimeBuckets[granularity]
+	type key struct{ entityType, entityName, bucket string }
+	var order []key
+	buckets := make(map[key]*UptimeBucket)
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT c.entity_type, c.entity_name, strftime(?, c.created_at), c.success, COALESCE(c.error_category, ''), COUNT(*), MAX(c.latency_ms)
+		 FROM `+from+`
+		 GROUP BY 1, 2, 3, 4, 5 ORDER BY 1, 2, 3`, format)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var k key
+		var success bool
+		var errCat string
+		var n int
+		var maxLat sql.NullInt64
+		if err := rows.Scan(&k.entityType, &k.entityName, &k.bucket, &success, &errCat, &n, &maxLat); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		b := buckets[k]
+		if b == nil {
+			b = &UptimeBucket{Errors: make(map[string]int)}
+			buckets[k] = b
+			order = append(order, k)
+		}
+		b.Total += n
+		switch {
+		case success:
+			b.Success += n
+			b.MaxMs = max(b.MaxMs, maxLat.Int64)
+		case errCat != "":
+			b.Errors[errCat] += n
+		default:
+			b.Errors["unknown"] += n
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT c.entity_type, c.entity_name, strftime(?, c.created_at), c.latency_ms
+		 FROM `+from+` AND c.success AND c.latency_ms IS NOT NULL
+		 ORDER BY 1, 2, 3, 4`, format)
+	if err != nil {
+		return err
+	}
+	var cur key
+	var lats []int64
+	flush := func() {
+		if b := buckets[cur]; b != nil && len(lats) > 0 {
+			b.P50Ms, b.P95Ms = stats.Percentile(lats, 50), stats.Percentile(lats, 95)
+		}
+		lats = lats[:0]
+	}
+	for rows.Next() {
+		var k key
+		var lat int64
+		if err := rows.Scan(&k.entityType, &k.entityName, &k.bucket, &lat); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if k != cur {
+			flush()
+			cur = k
+		}
+		lats = append(lats, lat)
+	}
+	flush()
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, k := range order {
+		b := buckets[k]
+		errs, _ := json.Marshal(b.Errors)
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO `+table+` (entity_type, entity_name, bucket, success, total, p50_ms, p95_ms, max_ms, errors)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(entity_type, entity_name, bucket) DO UPDATE SET
+			 success = excluded.success, total = excluded.total, p50_ms = excluded.p50_ms,
+			 p95_ms = excluded.p95_ms, max_ms = excluded.max_ms, errors = excluded.errors`,
+			k.entityType, k.entityName, k.bucket, b.Success, b.Total, b.P50Ms, b.P95Ms, b.MaxMs, string(errs)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UptimeBuckets returns an entity's rollups of the given granularity whose
+// bucket starts at or after since, oldest first.
+func (s *Store) UptimeBuckets(ctx context.Context, entityType, entityName, granularity string, since time.Time) ([]UptimeBucket, error) {
+	if _, ok := uptimeBuckets[granularity]; !ok {
+		return nil, fmt.Errorf("unknown uptime granularity %q", granularity)
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT bucket, success, total, p50_ms, p95_ms, max_ms, errors FROM uptime_`+granularity+`
+		 WHERE entity_type = ? AND entity_name = ? AND bucket >= ? ORDER BY bucket`,
+		entityType, entityName, since.UTC().Format(sqlTime))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []UptimeBucket
+	for rows.Next() {
+		b := UptimeBucket{EntityType: entityType, EntityName: entityName}
+		var bucket, errs string
+		if err := rows.Scan(&bucket, &b.Success, &b.Total, &b.P50Ms, &b.P95Ms, &b.MaxMs, &errs); err != nil {
+			return nil, err
+		}
+		b.Start, _ = parseTime(bucket)
+		_ = json.Unmarshal([]byte(errs), &b.Errors)
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,10 @@
+package stats
+
+// Percentile returns the nearest-rank percentile of sorted values.
+func Percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,16 @@
+package stats
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	vals := []int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140, 150, 160, 170, 180, 190, 2000}
+	if got := Percentile(vals, 50); got != 100 {
+		t.Errorf("p50 = %d", got)
+	}
+	if got := Percentile(vals, 95); got != 190 {
+		t.Errorf("p95 = %d, one outlier in 20 should not count", got)
+	}
+	if got := Percentile([]int64{7}, 95); got != 7 {
+		t.Errorf("single sample p95 = %d", got)
+	}
+}
@@ -3,11 +3,70 @@
   const incidentsUrl = '/v1/incidents?state=open';
   const statusIntervalMs = 10000;
   const incidentsIntervalMs = 20000;
+  const uptimeIntervalMs = 300000;
+  const uptimeDays = 90;
+  const uptime = {};
 
   function el(id) {
     return document.getElementById(id);
   }
 
+  function renderFamilies(families) {
+    if (!families || families.length === 0) return '';
+    return '<div class="families">' + families.map(function (f) {
+      const cls = f.healthy ? 'ok' : 'bad';
+      const detail = f.healthy ? (f.latency_ms != null ? f.latency_ms + ' ms' : 'ok') : (f.last_error || 'down');
+      return '<span class="family ' + cls + '">' + escapeHtml(f.family) + ': ' + escapeHtml(String(detail)) + '</span>';
+    }).join(' ') + '</div>';
+  }
+
+  function renderDependsOn(deps) {
+    if (!deps || deps.length === 0) return '';
+    return '<div class="depends">depends on ' + deps.map(escapeHtml).join(', ') + '</div>';
+  }
+
+  // renderUptime draws one bar per day from the cached 90-day rollup; days
+  // without checks stay grey.
+  function renderUptime(entity) {
+    const data = uptime[entity];
+    if (!data) return '';
+    const byDay = {};
+    data.buckets.forEach(function (b) { byDay[b.start.slice(0, 10)] = b; });
+    const today = new Date();
+    let bars = '';
+    for (let i = uptimeDays - 1; i >= 0; i--) {
+      const day = new Date(Date.UTC(today.getUTCFullYear(), today.getUTCMonth(), today.getUTCDate() - i)).toISOString().slice(0, 10);
+      const b = byDay[day];
+      let cls = 'none';
+      let title = day + ': no data';
+      if (b) {
+        cls = b.uptime_pct >= 99.9 ? 'ok' : (b.uptime_pct >= 99 ? 'warn' : 'bad');
+        title = day + ': ' + b.uptime_pct.toFixed(2) + '%';
+      }
+      bars += '<span class="' + cls + '" title="' + title + '"></span>';
+    }
+    const pct = data.uptime_pct != null ? data.uptime_pct.toFixed(2) + '% uptime (90 days)' : 'no uptime data';
+    return '<div class="uptime">' + bars + '</div><div class="uptime-pct">' + pct + '</div>';
+  }
+
+  function fetchUptime(entities) {
+    entities.forEach(function (entity) {
+      fetch('/v1/uptime?entity=' + encodeURIComponent(entity) + '&range=' + uptimeDays + 'd')
+        .then(function (r) { return r.ok ? r.json() : Promise.reject(r.status); })
+        .then(function (data) { uptime[entity] = data; })
+        .catch(function () {});
+    });
+  }
+
+  function renderSteps(steps) {
+    if (!steps || steps.length === 0) return '';
+    return '<div class="families">' + steps.map(function (s) {
+      const cls = s.success ? 'ok' : 'bad';
+      const detail = s.success ? s.latency_ms + ' ms' : (s.last_error || 'failed');
+      return '<span class="family ' + cls + '">' + escapeHtml(s.name) + ': ' + escapeHtml(String(detail)) + '</span>';
+    }).join(' → ') + '</div>';
+  }
+
   function renderRpc(container, data) {
     if (!data || !data.rpc_providers) return;
     container.innerHTML = data.rpc_providers.map(function (p) {
@@ -19,6 +78,10 @@
         '<div class="latency">' + lat + '</div>' +
         (p.url ? '<div class="url">' + escapeHtml(p.url) + '</div>' : '') +
         (p.last_error ? '<div class="error">' + escapeHtml(p.last_error) + '</div>' : '') +
+        (p.circuit_open ? '<div class="circuit">circuit open</div>' : '') +
+        renderFamilies(p.families) +
+        renderDependsOn(p.depends_on) +
+        renderUptime('rpc/' + p.name) +
         '</div>'
       );
     }).join('');
@@ -34,6 +97,48 @@
         '<div class="name">' + escapeHtml(d.name) + '</div>' +
         '<div class="latency">' + lat + '</div>' +
         (d.url ? '<div class="url">' + escapeHtml(d.url) + '</div>' : '') +
+        (d.last_error ? '<div class="error">' + escapeHtml(d.last_error) + '</div>' : '') +
+        (d.circuit_open ? '<div class="circuit">circuit open</div>' : '') +
+        renderSteps(d.steps) +
+        renderFamilies(d.families) +
+        renderDependsOn(d.depends_on) +
+        renderUptime('dapp/' + d.name) +
+        '</div>'
+      );
+    }).join('');
+  }
+
+  function renderTcp(container, data) {
+    if (!data || !data.tcp_targets || data.tcp_targets.length === 0) return;
+    el('tcp-section').hidden = false;
+    container.innerHTML = data.tcp_targets.map(function (t) {
+      const cls = t.healthy ? 'healthy' : 'unhealthy';
+      const lat = t.latency_ms != null ? t.latency_ms + ' ms' : '—';
+      return (
+        '<div class="card ' + cls + '">' +
+        '<div class="name">' + escapeHtml(t.name) + '</div>' +
+        '<div class="latency">' + lat + '</div>' +
+        (t.address ? '<div class="url">' + escapeHtml(t.address) + '</div>' : '') +
+        (t.last_error ? '<div class="error">' + escapeHtml(t.last_error) + '</div>' : '') +
+        (t.circuit_open ? '<div class="circuit">circuit open</div>' : '') +
+        renderFamilies(t.families) +
+        renderDependsOn(t.depends_on) +
+        '</div>'
+      );
+    }).join('');
+  }
+
+  function renderNodes(container, data) {
+    if (!data || !data.nodes || data.nodes.length === 0) return;
+    el('node-section').hidden = false;
+    container.innerHTML = data.nodes.map(function (n) {
+      const cls = n.healthy ? 'healthy' : 'unhealthy';
+      return (
+        '<div class="card ' + cls + '">' +
+        '<div class="name">' + escapeHtml(n.name) + '</div>' +
+        (n.last_error ? '<div class="error">' + escapeHtml(n.last_error) + '</div>' : '') +
+        (n.circuit_open ? '<div class="circuit">circuit open</div>' : '') +
+        renderDependsOn(n.depends_on) +
         '</div>'
       );
     }).join('');
@@ -47,15 +152,36 @@
     }
     listEl.innerHTML = data.map(function (i) {
       const cls = i.severity === 'CRIT' ? 'crit' : '';
+      const flap = i.flapping ? ', flapping ×' + i.flap_count : '';
+      const state = i.state ? ' <span class="state">' + escapeHtml(i.state) + flap + (i.acknowledged ? ', acknowledged' : '') + (i.parent_id ? ', part of #' + i.parent_id : '') + (i.impacted_by ? ', impacted by #' + i.impacted_by : '') + (i.report_count ? ', ' + i.report_count + (i.report_count === 1 ? ' user' : ' users') + ' affected' : '') + '</span>' : '';
       return (
         '<li class="' + cls + '">' +
-        '<strong>' + escapeHtml(i.entity_name) + '</strong> (' + escapeHtml(i.severity) + ') ' +
+        '<strong>' + escapeHtml(i.entity_name) + '</strong> (' + escapeHtml(i.severity) + ')' + state + ' ' +
         escapeHtml(i.summary) + ' <span class="muted">' + escapeHtml(i.started_at) + '</span>' +
         '</li>'
       );
     }).join('');
   }
 
+  function renderMaintenance(section, listEl, data) {
+    if (!section || !listEl) return;
+    if (!data || data.length === 0) {
+      section.hidden = true;
+      return;
+    }
+    section.hidden = false;
+    listEl.innerHTML = data.map(function (m) {
+      const scope = m.all ? 'All services' : (m.tag ? 'Tag ' + m.tag : m.entity);
+      const when = m.active ? 'in progress until ' + m.ends_at : m.starts_at + ' – ' + m.ends_at;
+      return (
+        '<li class="' + (m.active ? 'active' : '') + '">' +
+        '<strong>' + escapeHtml(scope) + '</strong> ' + escapeHtml(m.reason || '') +
+        ' <span class="muted">' + escapeHtml(when) + '</span>' +
+        '</li>'
+      );
+    }).join('');
+  }
+
   function escapeHtml(s) {
     if (s == null) return '';
     var div = document.createElement('div');
@@ -63,14 +189,24 @@
     return div.innerHTML;
   }
 
+  let uptimeFetchedAt = 0;
+
   function fetchStatus() {
     fetch(statusUrl)
       .then(function (r) { return r.ok ? r.json() : Promise.reject(r.status); })
       .then(function (data) {
+        if (Date.now() - uptimeFetchedAt >= uptimeIntervalMs) {
+          uptimeFetchedAt = Date.now();
+          fetchUptime((data.rpc_providers || []).map(function (p) { return 'rpc/' + p.name; })
+            .concat((data.dapps || []).map(function (d) { return 'dapp/' + d.name; })));
+        }
         el('recommended-rpc').textContent = data.recommended_provider || '—';
         renderRpc(el('rpc-cards'), data);
         renderDapps(el('dapp-cards'), data);
+        renderTcp(el('tcp-cards'), data);
+        renderNodes(el('node-cards'), data);
         renderIncidents(el('incidents-list'), data.open_incidents || []);
+        renderMaintenance(el('maintenance-section'), el('maintenance-list'), data.maintenance || []);
       })
       .catch(function () {
         el('recommended-rpc').textContent = '—';